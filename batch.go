@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// batchRace is one (config, events) pair of a batch.
+type batchRace struct {
+	cfgPath string
+	evsPath string
+}
+
+// batchResult is the outcome of one race of a batch.
+type batchResult struct {
+	race    batchRace
+	logs    []string
+	results []string
+	err     error
+}
+
+const batchUsage = "usage: batch [-workers n] [-out dir] [-manifest path] [-lang en|ru|no] [-utc] [<config_path> <events_paths>]..."
+
+// runBatch processes races concurrently on at most workers goroutines.
+// Results are returned in the order of races, whatever order the races
+// finish in.
+func runBatch(races []batchRace, workers int, base options) []batchResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	out := make([]batchResult, len(races))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(workers, len(races)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				opts := base
+				opts.cfgPath = races[i].cfgPath
+				opts.evsPath = races[i].evsPath
+
+				logs, results, err := runApp(opts)
+				out[i] = batchResult{race: races[i], logs: logs, results: results, err: err}
+			}
+		}()
+	}
+
+	for i := range races {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return out
+}
+
+// runBatchCommand processes many races in parallel, printing the results of
+// each race in input order. Races come from the arguments as config/events
+// pairs and from a manifest file with one "config events" pair per line.
+func runBatchCommand(args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	workers := fs.Int("workers", runtime.NumCPU(), "number of races processed at the same time")
+	outDir := fs.String("out", "", "write the logs and results of each race to this directory")
+	manifest := fs.String("manifest", "", "file listing races, one \"config events\" pair per line")
+	lang := fs.String("lang", "", "output language")
+	utc := fs.Bool("utc", false, "render times in UTC instead of the race time zone")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg()%2 != 0 {
+		return fmt.Errorf(batchUsage)
+	}
+
+	var races []batchRace
+	if *manifest != "" {
+		var err error
+		races, err = loadManifest(*manifest)
+		if err != nil {
+			return err
+		}
+	}
+	for i := 0; i < fs.NArg(); i += 2 {
+		races = append(races, batchRace{cfgPath: fs.Arg(i), evsPath: fs.Arg(i + 1)})
+	}
+	if len(races) == 0 {
+		return fmt.Errorf(batchUsage)
+	}
+
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			return fmt.Errorf("error creating output directory: %v", err)
+		}
+	}
+
+	failed := 0
+	for i, res := range runBatch(races, *workers, options{lang: *lang, utc: *utc}) {
+		fmt.Printf("===Race %d: %s %s===\n", i+1, res.race.cfgPath, res.race.evsPath)
+		if res.err != nil {
+			failed++
+			fmt.Printf("error: %v\n\n", res.err)
+			continue
+		}
+
+		for _, row := range res.results {
+			fmt.Println(row)
+		}
+		fmt.Println()
+
+		if *outDir != "" {
+			logPath := filepath.Join(*outDir, fmt.Sprintf("race%d_logs.txt", i+1))
+			resPath := filepath.Join(*outDir, fmt.Sprintf("race%d_results.txt", i+1))
+			if err := writeLinesToFile(logPath, res.logs); err != nil {
+				return fmt.Errorf("error writing logs of race %d: %v", i+1, err)
+			}
+			if err := writeLinesToFile(resPath, res.results); err != nil {
+				return fmt.Errorf("error writing results of race %d: %v", i+1, err)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d races failed", failed, len(races))
+	}
+	return nil
+}
+
+// loadManifest reads races from a file with one "config events" pair per
+// line. Blank lines and lines starting with # are skipped.
+func loadManifest(path string) ([]batchRace, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening manifest: %v", err)
+	}
+	defer file.Close()
+
+	var races []batchRace
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"config events\", got '%s'", path, lineNum, line)
+		}
+		races = append(races, batchRace{cfgPath: fields[0], evsPath: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading manifest: %v", err)
+	}
+	return races, nil
+}
@@ -6,12 +6,16 @@ type Competitor struct {
 	ID           int
 	NotStarted   bool
 	NotFinished  bool
+	Disqualified bool
+	DsqReason    string
+	DnfReason    string
 	StartTime    time.Time
 	FinishTime   time.Time
 	PlannedStart time.Time
 	ActualStart  time.Time
 	TotalHits    int
 	CurrentHits  int
+	StageHits    []int
 
 	CurPenaltyStart  time.Time
 	CurPenaltyEnd    time.Time
@@ -22,6 +26,21 @@ type Competitor struct {
 	CurLapEnd     time.Time
 	LapDurations  []time.Duration
 	TotalDuration time.Duration
+
+	Penalties []Penalty
+}
+
+const (
+	PENALTY_SOURCE_JURY        = "jury"
+	PENALTY_SOURCE_MISSED_SHOT = "missed shot"
+)
+
+// Penalty is a time penalty added to the competitor's final time.
+type Penalty struct {
+	Time     time.Time
+	Source   string
+	Duration time.Duration
+	Reason   string
 }
 
 func (c *Competitor) EnterPenalty(t time.Time) {
@@ -37,6 +56,11 @@ func (c *Competitor) ExitPenalty(t time.Time, pLen int) {
 	c.TotalPenaltyTime += penaltyDuration
 }
 
+// EndStage records the hits of the firing line the competitor just left.
+func (c *Competitor) EndStage() {
+	c.StageHits = append(c.StageHits, c.CurrentHits)
+}
+
 func (c *Competitor) EndLap(t time.Time) {
 	c.CurLapEnd = t
 	duration := t.Sub(c.CurLapStart)
@@ -44,3 +68,25 @@ func (c *Competitor) EndLap(t time.Time) {
 	c.TotalDuration += duration
 	c.CurLapStart = t
 }
+
+func (c *Competitor) AddTimePenalty(p Penalty) {
+	c.Penalties = append(c.Penalties, p)
+}
+
+func (c *Competitor) PenaltyDuration() time.Duration {
+	var total time.Duration
+	for _, p := range c.Penalties {
+		total += p.Duration
+	}
+	return total
+}
+
+// FinalDuration is the race time including all time penalties.
+func (c *Competitor) FinalDuration() time.Duration {
+	return c.TotalDuration + c.PenaltyDuration()
+}
+
+func (c *Competitor) Disqualify(reason string) {
+	c.Disqualified = true
+	c.DsqReason = reason
+}
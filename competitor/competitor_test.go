@@ -32,3 +32,18 @@ func TestExitPenalty(t *testing.T) {
 		t.Errorf("Expected penalty time 10s, got %v", c.TotalPenaltyTime)
 	}
 }
+
+func TestFinalDuration(t *testing.T) {
+	c := &Competitor{TotalDuration: 10 * time.Minute}
+
+	c.AddTimePenalty(Penalty{Source: PENALTY_SOURCE_JURY, Duration: time.Minute})
+	c.AddTimePenalty(Penalty{Source: PENALTY_SOURCE_MISSED_SHOT, Duration: 2 * time.Minute})
+
+	if c.PenaltyDuration() != 3*time.Minute {
+		t.Errorf("Expected penalty duration 3m, got %v", c.PenaltyDuration())
+	}
+
+	if c.FinalDuration() != 13*time.Minute {
+		t.Errorf("Expected final duration 13m, got %v", c.FinalDuration())
+	}
+}
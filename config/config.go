@@ -1,7 +1,6 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"time"
@@ -9,14 +8,36 @@ import (
 
 const TIME_FORMAT_NO_MS = "15:04:05"
 const TIME_FORMAT_WITH_MS = "15:04:05.000"
+const DATE_FORMAT = "2006-01-02"
+const DATETIME_FORMAT_WITH_MS = "2006-01-02 15:04:05.000"
+
+// ROLLOVER_THRESHOLD is how far a clock time may be from the reference
+// before it is considered to belong to the next or the previous day.
+const ROLLOVER_THRESHOLD = 12 * time.Hour
 
 type ConfigRaw struct {
-	Laps        int    `json:"laps"`
-	LapLen      int    `json:"lapLen"`
-	PenaltyLen  int    `json:"penaltyLen"`
-	FiringLines int    `json:"firingLines"`
-	Start       string `json:"start"`
-	StartDelta  string `json:"startDelta"`
+	Laps        int    `json:"laps" yaml:"laps" toml:"laps"`
+	LapLen      int    `json:"lapLen" yaml:"lapLen" toml:"lapLen"`
+	PenaltyLen  int    `json:"penaltyLen" yaml:"penaltyLen" toml:"penaltyLen"`
+	FiringLines int    `json:"firingLines" yaml:"firingLines" toml:"firingLines"`
+	Start       string `json:"start" yaml:"start" toml:"start"`
+	StartDelta  string `json:"startDelta" yaml:"startDelta" toml:"startDelta"`
+	MissPenalty string `json:"missPenalty,omitempty" yaml:"missPenalty,omitempty" toml:"missPenalty,omitempty"`
+	Date        string `json:"date,omitempty" yaml:"date,omitempty" toml:"date,omitempty"`
+	Timezone    string `json:"timezone,omitempty" yaml:"timezone,omitempty" toml:"timezone,omitempty"`
+
+	Name  string   `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Venue string   `json:"venue,omitempty" yaml:"venue,omitempty" toml:"venue,omitempty"`
+	Jury  []string `json:"jury,omitempty" yaml:"jury,omitempty" toml:"jury,omitempty"`
+
+	// unknownFields are keys of the config file not matching any field
+	unknownFields []string
+	// fileKeys are all keys present in the config file
+	fileKeys []string
+	// origins maps a field to where its value came from
+	origins map[string]string
+	// ignoredEnv are BIATHLON_* variables not matching any field
+	ignoredEnv []string
 }
 
 type Config struct {
@@ -26,32 +47,92 @@ type Config struct {
 	FiringLines int
 	Start       time.Time
 	StartDelta  time.Duration
+	MissPenalty time.Duration
+	Date        time.Time
+	Location    *time.Location
+
+	Name  string
+	Venue string
+	Jury  []string
 }
 
+// LoadConfig reads a JSON, YAML or TOML config, chosen by file extension.
 func LoadConfig(path string) (*Config, error) {
-
-	raw, err := os.ReadFile(path)
+	rawCfg, err := LoadRaw(path)
 	if err != nil {
-		return nil, fmt.Errorf("error while reading file")
+		return nil, err
 	}
 
-	var rawCfg ConfigRaw
-	err = json.Unmarshal(raw, &rawCfg)
+	return rawCfg.Build()
+}
+
+// LoadRaw reads a config file without validating it, so overrides can be
+// applied before Build.
+func LoadRaw(path string) (*ConfigRaw, error) {
+
+	raw, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("error while parsing json")
+		return nil, fmt.Errorf("error while reading file: %w", err)
 	}
 
-	startTime, err := rawCfg.parseStartTime()
+	rawCfg, err := DecodeRaw(raw, FormatFromPath(path))
 	if err != nil {
 		return nil, err
+	}
 
+	for _, key := range rawCfg.fileKeys {
+		rawCfg.setOrigin(key, path)
 	}
+	return rawCfg, nil
+}
 
-	startDelta, err := rawCfg.parseDelta()
+// ParseConfig parses a JSON config.
+func ParseConfig(raw []byte) (*Config, error) {
+	rawCfg, err := DecodeRaw(raw, FORMAT_JSON)
 	if err != nil {
 		return nil, err
 	}
 
+	return rawCfg.Build()
+}
+
+// Build validates the raw config and converts it into a Config. All
+// problems are reported together as ValidationErrors.
+func (rawCfg *ConfigRaw) Build() (*Config, error) {
+	errs := rawCfg.validate()
+
+	loc, err := rawCfg.parseLocation()
+	if err != nil {
+		errs.add("timezone", err)
+		loc = time.UTC
+	}
+
+	date, err := rawCfg.parseDate(loc)
+	errs.add("date", err)
+
+	var startTime time.Time
+	if rawCfg.Start != "" {
+		startTime, err = rawCfg.parseStartTime()
+		errs.add("start", err)
+		startTime = AtDate(date, startTime)
+	}
+
+	var startDelta time.Duration
+	if rawCfg.StartDelta != "" {
+		startDelta, err = rawCfg.parseDelta()
+		errs.add("startDelta", err)
+	}
+
+	var missPenalty time.Duration
+	if rawCfg.MissPenalty != "" {
+		missPenalty, err = ParseDuration(rawCfg.MissPenalty)
+		errs.add("missPenalty", err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
 	return &Config{
 		Laps:        rawCfg.Laps,
 		LapLen:      rawCfg.LapLen,
@@ -59,31 +140,77 @@ func LoadConfig(path string) (*Config, error) {
 		FiringLines: rawCfg.FiringLines,
 		Start:       startTime,
 		StartDelta:  startDelta,
+		MissPenalty: missPenalty,
+		Date:        date,
+		Location:    loc,
+		Name:        rawCfg.Name,
+		Venue:       rawCfg.Venue,
+		Jury:        rawCfg.Jury,
 	}, nil
 }
 
+// parseLocation returns the race time zone, UTC when the config has none.
+func (rawCfg *ConfigRaw) parseLocation() (*time.Location, error) {
+	if rawCfg.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(rawCfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("must be an IANA time zone name like Europe/Oslo, got %q", rawCfg.Timezone)
+	}
+	return loc, nil
+}
+
+// parseDate returns the race date, or year 0 when the config has none.
+func (rawCfg *ConfigRaw) parseDate(loc *time.Location) (time.Time, error) {
+	if rawCfg.Date == "" {
+		return time.Date(0, 1, 1, 0, 0, 0, 0, loc), nil
+	}
+
+	date, err := time.ParseInLocation(DATE_FORMAT, rawCfg.Date, loc)
+	if err != nil {
+		return time.Date(0, 1, 1, 0, 0, 0, 0, loc), fmt.Errorf("must be a date yyyy-mm-dd, got %q", rawCfg.Date)
+	}
+	return date, nil
+}
+
 func (rawCfg *ConfigRaw) parseStartTime() (time.Time, error) {
 	stTime, err := time.Parse(TIME_FORMAT_NO_MS, rawCfg.Start)
 	if err != nil {
 		stTime, err = time.Parse(TIME_FORMAT_WITH_MS, rawCfg.Start)
 		if err != nil {
-			return stTime, fmt.Errorf("error while formatting start time: %v", err)
+			return stTime, fmt.Errorf("must be a time of day hh:mm:ss[.mmm], got %q", rawCfg.Start)
 		}
 	}
 	return stTime, nil
 }
 
 func (rawCfg *ConfigRaw) parseDelta() (time.Duration, error) {
-	pDelta, err := time.Parse(TIME_FORMAT_NO_MS, rawCfg.StartDelta)
-	if err != nil {
-		pDelta, err = time.Parse(TIME_FORMAT_WITH_MS, rawCfg.StartDelta)
-		if err != nil {
-			return 0, fmt.Errorf("error while formatting start delta: %v", err)
-		}
-	}
+	return ParseDuration(rawCfg.StartDelta)
+}
+
+// AtDate returns the clock time of clock on the day of date.
+func AtDate(date, clock time.Time) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), date.Location())
+}
 
-	formattedDelta := time.Duration(
-		pDelta.Hour()*int(time.Hour) + pDelta.Minute()*int(time.Minute) + pDelta.Second()*int(time.Second),
-	)
-	return formattedDelta, nil
+// InLocation returns the time with the same wall clock as t in loc.
+func InLocation(t time.Time, loc *time.Location) time.Time {
+	return AtDate(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), t)
+}
+
+// AnchorClock places clock on the day closest to ref: the next day when it
+// would otherwise be more than ROLLOVER_THRESHOLD before ref, the previous
+// day when it would be more than ROLLOVER_THRESHOLD after ref.
+func AnchorClock(ref, clock time.Time) time.Time {
+	t := AtDate(ref, clock)
+	switch {
+	case t.Before(ref.Add(-ROLLOVER_THRESHOLD)):
+		t = t.AddDate(0, 0, 1)
+	case t.After(ref.Add(ROLLOVER_THRESHOLD)):
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
 }
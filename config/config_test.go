@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -97,3 +99,365 @@ func TestLoadConfig_FileNotFound(t *testing.T) {
 		t.Errorf("Expected nil, got %v", cfg)
 	}
 }
+
+func TestLoadConfig_Date(t *testing.T) {
+
+	cfgContent := `{
+		"laps": 1,
+		"lapLen": 120,
+		"penaltyLen": 30,
+		"firingLines": 1,
+		"start": "23:30:00",
+		"startDelta": "00:00:30",
+		"date": "2025-12-31"
+	}`
+
+	cfg, err := ParseConfig([]byte(cfgContent))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+
+	expectedStart := time.Date(2025, 12, 31, 23, 30, 0, 0, time.UTC)
+	if !cfg.Start.Equal(expectedStart) {
+		t.Errorf("Expected Start = %v, got %v", expectedStart, cfg.Start)
+	}
+}
+
+func TestAnchorClock(t *testing.T) {
+	ref := time.Date(2025, 12, 31, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		clock    time.Time
+		expected time.Time
+	}{
+		{time.Date(0, 1, 1, 23, 45, 0, 0, time.UTC), time.Date(2025, 12, 31, 23, 45, 0, 0, time.UTC)},
+		{time.Date(0, 1, 1, 22, 0, 0, 0, time.UTC), time.Date(2025, 12, 31, 22, 0, 0, 0, time.UTC)},
+		{time.Date(0, 1, 1, 0, 15, 0, 0, time.UTC), time.Date(2026, 1, 1, 0, 15, 0, 0, time.UTC)},
+	}
+
+	// Shortly after midnight, a late clock time belongs to the previous day
+	after := time.Date(2026, 1, 1, 0, 0, 1, 0, time.UTC)
+	if got := AnchorClock(after, time.Date(0, 1, 1, 23, 59, 59, 0, time.UTC)); !got.Equal(time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("Expected 23:59:59 to move back to 2025-12-31, got %v", got)
+	}
+
+	for _, tt := range tests {
+		if got := AnchorClock(ref, tt.clock); !got.Equal(tt.expected) {
+			t.Errorf("AnchorClock(%v) = %v, want %v", tt.clock, got, tt.expected)
+		}
+	}
+}
+
+func TestParseConfig_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "non-positive values",
+			content:  `{"laps": 0, "lapLen": -1, "penaltyLen": 0, "firingLines": 0, "start": "10:00:00", "startDelta": "00:01:30"}`,
+			expected: []string{"laps must be >= 1, got 0", "lapLen must be >= 1, got -1", "penaltyLen must be >= 1, got 0", "firingLines must be >= 1, got 0"},
+		},
+		{
+			name:     "more firing lines than laps",
+			content:  `{"laps": 2, "lapLen": 1, "penaltyLen": 1, "firingLines": 3, "start": "10:00:00", "startDelta": "00:01:30"}`,
+			expected: []string{"firingLines must be <= laps (2), got 3"},
+		},
+		{
+			name:     "bad times",
+			content:  `{"laps": 1, "lapLen": 1, "penaltyLen": 1, "firingLines": 1, "start": "ten", "startDelta": ""}`,
+			expected: []string{"startDelta is required", `start must be a time of day hh:mm:ss[.mmm], got "ten"`},
+		},
+		{
+			name:     "unknown field",
+			content:  `{"laps": 1, "lapLen": 1, "penaltyLen": 1, "firingLines": 1, "start": "10:00:00", "startDelta": "00:01:30", "lapz": 2}`,
+			expected: []string{"lapz is not a known field"},
+		},
+		{
+			name:     "wrong case",
+			content:  `{"Laps": 2, "lapLen": 1, "penaltyLen": 1, "firingLines": 1, "start": "10:00:00", "startDelta": "00:01:30"}`,
+			expected: []string{"laps must be >= 1, got 0", "Laps is not a known field"},
+		},
+		{
+			name:     "wrong type",
+			content:  `{"laps": "five"}`,
+			expected: []string{"laps must be int, got string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.content))
+
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("Expected ValidationErrors, got %v", err)
+			}
+			if len(verrs) != len(tt.expected) {
+				t.Fatalf("Expected %d errors, got %d: %v", len(tt.expected), len(verrs), verrs)
+			}
+			for i, msg := range tt.expected {
+				if verrs[i].Error() != msg {
+					t.Errorf("Expected error %q, got %q", msg, verrs[i].Error())
+				}
+			}
+		})
+	}
+}
+
+func TestParseConfig_SyntaxError(t *testing.T) {
+	_, err := ParseConfig([]byte("{\n  \"laps\": 1,\n}"))
+
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected error pointing at line 3, got %v", err)
+	}
+}
+
+func TestLoadConfig_Formats(t *testing.T) {
+	for _, path := range []string{"../testdata/config.yaml", "../testdata/config.toml"} {
+		t.Run(path, func(t *testing.T) {
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			if cfg.Laps != 2 || cfg.LapLen != 3500 || cfg.PenaltyLen != 150 || cfg.FiringLines != 2 {
+				t.Errorf("Unexpected config %+v", cfg)
+			}
+			expectedStart := time.Date(2025, 2, 14, 10, 0, 0, 0, time.UTC)
+			if !cfg.Start.Equal(expectedStart) {
+				t.Errorf("Expected Start = %v, got %v", expectedStart, cfg.Start)
+			}
+			if cfg.StartDelta != 90*time.Second {
+				t.Errorf("Expected StartDelta = 1m30s, got %v", cfg.StartDelta)
+			}
+		})
+	}
+}
+
+func TestDecodeRaw_SameValidation(t *testing.T) {
+	contents := map[string]string{
+		FORMAT_JSON: `{"laps": 0, "lapLen": 1, "penaltyLen": 1, "firingLines": 1, "start": "10:00:00", "startDelta": "00:01:30", "lapz": 1}`,
+		FORMAT_YAML: "laps: 0\nlapLen: 1\npenaltyLen: 1\nfiringLines: 1\nstart: '10:00:00'\nstartDelta: '00:01:30'\nlapz: 1\n",
+		FORMAT_TOML: "laps = 0\nlapLen = 1\npenaltyLen = 1\nfiringLines = 1\nstart = '10:00:00'\nstartDelta = '00:01:30'\nlapz = 1\n",
+	}
+	expected := "laps must be >= 1, got 0; lapz is not a known field"
+
+	for format, content := range contents {
+		t.Run(format, func(t *testing.T) {
+			rawCfg, err := DecodeRaw([]byte(content), format)
+			if err != nil {
+				t.Fatalf("DecodeRaw() error = %v", err)
+			}
+
+			_, err = rawCfg.Build()
+			if err == nil || err.Error() != expected {
+				t.Errorf("Expected error %q, got %v", expected, err)
+			}
+		})
+	}
+}
+
+func TestDecodeRaw_SameTypeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents map[string]string
+		expected string
+	}{
+		{
+			name: "string for int",
+			contents: map[string]string{
+				FORMAT_JSON: `{"laps": "two"}`,
+				FORMAT_YAML: "laps: two\n",
+				FORMAT_TOML: "laps = \"two\"\n",
+			},
+			expected: "laps must be int, got string",
+		},
+		{
+			name: "number for string",
+			contents: map[string]string{
+				FORMAT_JSON: `{"start": 10}`,
+				FORMAT_YAML: "start: 10\n",
+				FORMAT_TOML: "start = 10\n",
+			},
+			expected: "start must be string, got number",
+		},
+		{
+			name: "list for int",
+			contents: map[string]string{
+				FORMAT_JSON: `{"lapLen": [1, 2]}`,
+				FORMAT_YAML: "lapLen: [1, 2]\n",
+				FORMAT_TOML: "lapLen = [1, 2]\n",
+			},
+			expected: "lapLen must be int, got array",
+		},
+	}
+
+	for _, tt := range tests {
+		for format, content := range tt.contents {
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				_, err := DecodeRaw([]byte(content), format)
+
+				var verrs ValidationErrors
+				if !errors.As(err, &verrs) {
+					t.Fatalf("Expected ValidationErrors, got %v", err)
+				}
+				if err.Error() != tt.expected {
+					t.Errorf("Expected error %q, got %q", tt.expected, err.Error())
+				}
+			})
+		}
+	}
+}
+
+func TestDecodeRaw_UnquotedTimes(t *testing.T) {
+	contents := map[string]string{
+		FORMAT_YAML: "laps: 1\nlapLen: 1\npenaltyLen: 1\nfiringLines: 1\nstart: 10:00:00\nstartDelta: 00:01:30\ndate: 2025-02-14\n",
+		FORMAT_TOML: "laps = 1\nlapLen = 1\npenaltyLen = 1\nfiringLines = 1\nstart = 10:00:00\nstartDelta = 00:01:30\ndate = 2025-02-14\n",
+	}
+
+	for format, content := range contents {
+		t.Run(format, func(t *testing.T) {
+			rawCfg, err := DecodeRaw([]byte(content), format)
+			if err != nil {
+				t.Fatalf("DecodeRaw() error = %v", err)
+			}
+			if rawCfg.Start != "10:00:00" || rawCfg.StartDelta != "00:01:30" || rawCfg.Date != "2025-02-14" {
+				t.Errorf("Unexpected config %+v", rawCfg)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		err      bool
+	}{
+		{input: "00:01:30", expected: 90 * time.Second},
+		{input: "00:00:01.250", expected: 1250 * time.Millisecond},
+		{input: "25:00:00", expected: 25 * time.Hour},
+		{input: "90s", expected: 90 * time.Second},
+		{input: "1m30.5s", expected: 90*time.Second + 500*time.Millisecond},
+		{input: "90", expected: 90 * time.Second},
+		{input: "1.5", expected: 1500 * time.Millisecond},
+		{input: "00:61:00", err: true},
+		{input: "1:2", err: true},
+		{input: "01:-5:00", err: true},
+		{input: "+1:00:00", err: true},
+		{input: "00:01:+5", err: true},
+		{input: "00:00:01.-50", err: true},
+		{input: "-5s", err: true},
+		{input: "NaN", err: true},
+		{input: "Inf", err: true},
+		{input: "1_000", err: true},
+		{input: "1e3", err: true},
+		{input: "1e12", err: true},
+		{input: "99999999999", err: true},
+		{input: ".5", err: true},
+		{input: "9223372036.5", err: true},
+		{input: "soon", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDuration(tt.input)
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseDuration() error = %v, wantErr %v", err, tt.err)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseDuration() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_StartDeltaMillis(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"laps": 1, "lapLen": 1, "penaltyLen": 1, "firingLines": 1, "start": "10:00:00", "startDelta": "00:00:30.500"}`))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+
+	if cfg.StartDelta != 30500*time.Millisecond {
+		t.Errorf("Expected StartDelta = 30.5s, got %v", cfg.StartDelta)
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	rawCfg, err := LoadRaw("../testdata/config.json")
+	if err != nil {
+		t.Fatalf("LoadRaw() error = %v", err)
+	}
+
+	env := []string{"HOME=/root", "BIATHLON_START_DELTA=45s", "BIATHLON_LAPS=4", "BIATHLON_HOME=/opt/biathlon"}
+	if err := rawCfg.ApplyEnv(env); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+	if ignored := rawCfg.IgnoredEnv(); len(ignored) != 1 || ignored[0] != "BIATHLON_HOME" {
+		t.Errorf("Expected BIATHLON_HOME to be ignored, got %v", ignored)
+	}
+	if err := rawCfg.ApplySets([]string{"laps=3"}); err != nil {
+		t.Fatalf("ApplySets() error = %v", err)
+	}
+
+	cfg, err := rawCfg.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if cfg.Laps != 3 {
+		t.Errorf("Expected --set to win, got Laps = %d", cfg.Laps)
+	}
+	if cfg.StartDelta != 45*time.Second {
+		t.Errorf("Expected StartDelta = 45s, got %v", cfg.StartDelta)
+	}
+
+	origins := map[string]string{}
+	for _, f := range rawCfg.Origins() {
+		origins[f.Field] = f.Origin
+	}
+	expected := map[string]string{
+		"laps":       "--set laps",
+		"startDelta": "BIATHLON_START_DELTA",
+		"lapLen":     "../testdata/config.json",
+		"date":       ORIGIN_DEFAULT,
+	}
+	for field, origin := range expected {
+		if origins[field] != origin {
+			t.Errorf("Expected %s from %q, got %q", field, origin, origins[field])
+		}
+	}
+}
+
+func TestOverrides_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []string
+		sets []string
+	}{
+		{name: "bad env int", env: []string{"BIATHLON_LAPS=many"}},
+		{name: "bad int", sets: []string{"laps=many"}},
+		{name: "unknown key", sets: []string{"lapz=1"}},
+		{name: "missing value", sets: []string{"laps"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawCfg := &ConfigRaw{}
+			err := rawCfg.ApplyEnv(tt.env)
+			if err == nil {
+				err = rawCfg.ApplySets(tt.sets)
+			}
+			if err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("startDelta"); got != "BIATHLON_START_DELTA" {
+		t.Errorf("Expected BIATHLON_START_DELTA, got %s", got)
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const durationFormats = "hh:mm:ss[.mmm], a Go duration like 90s or plain seconds"
+
+// ParseDuration parses a non-negative duration written as hh:mm:ss[.mmm]
+// (hours are not limited to 24), a Go duration string like "1m30s", or a
+// plain number of seconds like "90" or "1.5".
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	var d time.Duration
+	var err error
+
+	switch {
+	case strings.Contains(s, ":"):
+		d, err = parseClockDuration(s)
+
+	case isNumber(s):
+		d, err = parseSeconds(s)
+
+	default:
+		d, err = time.ParseDuration(s)
+	}
+
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("must be a duration %s, got %q", durationFormats, s)
+	}
+	return d, nil
+}
+
+func parseClockDuration(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("expected hh:mm:ss")
+	}
+
+	hours, ok := digits(parts[0])
+	if !ok {
+		return 0, fmt.Errorf("invalid hours")
+	}
+
+	minutes, ok := digits(parts[1])
+	if !ok || len(parts[1]) != 2 || minutes > 59 {
+		return 0, fmt.Errorf("invalid minutes")
+	}
+
+	secStr, msStr, hasMs := strings.Cut(parts[2], ".")
+	seconds, ok := digits(secStr)
+	if !ok || len(secStr) != 2 || seconds > 59 {
+		return 0, fmt.Errorf("invalid seconds")
+	}
+
+	var millis int
+	if hasMs {
+		millis, ok = digits(msStr)
+		if !ok || len(msStr) != 3 {
+			return 0, fmt.Errorf("invalid milliseconds")
+		}
+	}
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second +
+		time.Duration(millis)*time.Millisecond, nil
+}
+
+// digits parses a clock component made of decimal digits only, rejecting
+// signs that strconv.Atoi would accept.
+func digits(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
+
+// isNumber reports whether s is plain decimal seconds: digits with an
+// optional fraction, like "90" or "1.5".
+func isNumber(s string) bool {
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if _, ok := digits(whole); !ok {
+		return false
+	}
+	if hasFrac {
+		_, ok := digits(frac)
+		return ok
+	}
+	return true
+}
+
+// maxSeconds is the largest number of seconds a time.Duration holds.
+const maxSeconds = float64(math.MaxInt64 / int64(time.Second))
+
+func parseSeconds(s string) (time.Duration, error) {
+	secs, err := strconv.ParseFloat(s, 64)
+	if err != nil || secs > maxSeconds {
+		return 0, fmt.Errorf("invalid seconds")
+	}
+	return time.Duration(secs * float64(time.Second)).Round(time.Millisecond), nil
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FORMAT_JSON = "json"
+	FORMAT_YAML = "yaml"
+	FORMAT_TOML = "toml"
+)
+
+// FormatFromPath detects the config format by file extension, defaulting
+// to JSON.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FORMAT_YAML
+	case ".toml":
+		return FORMAT_TOML
+	default:
+		return FORMAT_JSON
+	}
+}
+
+// DecodeRaw decodes a config in the given format without validating it.
+func DecodeRaw(raw []byte, format string) (*ConfigRaw, error) {
+	switch format {
+	case FORMAT_JSON:
+		return decodeJSON(raw)
+	case FORMAT_YAML:
+		return decodeYAML(raw)
+	case FORMAT_TOML:
+		return decodeTOML(raw)
+	}
+	return nil, fmt.Errorf("unsupported config format: %s", format)
+}
+
+// decodeYAML and decodeTOML turn the document into JSON and decode it with
+// decodeJSON, so every format gets the same validation and error messages.
+func decodeYAML(raw []byte) (*ConfigRaw, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("error while parsing yaml: %v", err)
+	}
+
+	fields := map[string]any{}
+	if len(doc.Content) > 0 {
+		root := doc.Content[0]
+		if root.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("error while parsing yaml: line %d: expected a mapping of config fields", root.Line)
+		}
+
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			value, err := yamlValue(root.Content[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("error while parsing yaml: line %d: %v", root.Content[i+1].Line, err)
+			}
+			fields[root.Content[i].Value] = value
+		}
+	}
+
+	return decodeFields(fields, "yaml")
+}
+
+// yamlValue returns the value of a node as decoded from JSON. Scalars other
+// than numbers, booleans and null stay strings, so unquoted dates and times
+// read as written.
+func yamlValue(node *yaml.Node) (any, error) {
+	if node.Kind != yaml.ScalarNode {
+		var value any
+		err := node.Decode(&value)
+		return value, err
+	}
+
+	switch node.ShortTag() {
+	case "!!int":
+		var n int64
+		err := node.Decode(&n)
+		return n, err
+	case "!!float":
+		var f float64
+		err := node.Decode(&f)
+		return f, err
+	case "!!bool":
+		var b bool
+		err := node.Decode(&b)
+		return b, err
+	case "!!null":
+		return nil, nil
+	}
+	return node.Value, nil
+}
+
+func decodeTOML(raw []byte) (*ConfigRaw, error) {
+	fields := map[string]any{}
+	if _, err := toml.Decode(string(raw), &fields); err != nil {
+		return nil, fmt.Errorf("error while parsing toml: %v", err)
+	}
+
+	// Unquoted dates and times read as written
+	for key, value := range fields {
+		if t, ok := value.(time.Time); ok {
+			fields[key] = tomlTime(t)
+		}
+	}
+
+	return decodeFields(fields, "toml")
+}
+
+// tomlTime formats a TOML date or time back to text. The decoder marks
+// local dates and times with their own locations.
+func tomlTime(t time.Time) string {
+	switch t.Location().String() {
+	case "date-local":
+		return t.Format(DATE_FORMAT)
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func decodeFields(fields map[string]any, format string) (*ConfigRaw, error) {
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing %s: %v", format, err)
+	}
+	return decodeJSON(raw)
+}
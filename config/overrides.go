@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const ENV_PREFIX = "BIATHLON_"
+
+const ORIGIN_DEFAULT = "default"
+
+// FieldOrigin is the effective value of a config field and where it came from.
+type FieldOrigin struct {
+	Field  string
+	Value  string
+	Origin string
+}
+
+// Set overrides a single field by its config name, e.g. Set("laps", "3").
+// List fields take comma-separated values.
+func (rawCfg *ConfigRaw) Set(key, value, origin string) error {
+	idx, ok := fieldIndex(key)
+	if !ok {
+		return FieldError{Field: key, Message: "is not a known field"}
+	}
+
+	field := reflect.ValueOf(rawCfg).Elem().Field(idx)
+	switch field.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return FieldError{Field: key, Message: fmt.Sprintf("must be int, got %q", value)}
+		}
+		field.SetInt(int64(n))
+
+	case reflect.String:
+		field.SetString(value)
+
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	}
+
+	rawCfg.setOrigin(key, origin)
+	return nil
+}
+
+// ApplySets applies key=value overrides, e.g. from --set flags.
+func (rawCfg *ConfigRaw) ApplySets(sets []string) error {
+	for _, set := range sets {
+		key, value, found := strings.Cut(set, "=")
+		if !found {
+			return fmt.Errorf("invalid override %q, expected key=value", set)
+		}
+
+		if err := rawCfg.Set(strings.TrimSpace(key), value, "--set "+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyEnv applies BIATHLON_* variables from environ (as returned by
+// os.Environ). Field names are upper snake case: startDelta is
+// BIATHLON_START_DELTA. Variables not matching any field are skipped, see
+// IgnoredEnv.
+func (rawCfg *ConfigRaw) ApplyEnv(environ []string) error {
+	byEnv := map[string]string{}
+	for _, name := range fieldNames() {
+		byEnv[EnvName(name)] = name
+	}
+
+	sorted := append([]string(nil), environ...)
+	sort.Strings(sorted)
+
+	for _, kv := range sorted {
+		env, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(env, ENV_PREFIX) {
+			continue
+		}
+
+		key, ok := byEnv[env]
+		if !ok {
+			rawCfg.ignoredEnv = append(rawCfg.ignoredEnv, env)
+			continue
+		}
+		if err := rawCfg.Set(key, value, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IgnoredEnv returns the BIATHLON_* variables skipped by ApplyEnv because
+// they don't match any config field.
+func (rawCfg *ConfigRaw) IgnoredEnv() []string {
+	return rawCfg.ignoredEnv
+}
+
+// EnvName returns the environment variable overriding a config field.
+func EnvName(field string) string {
+	var b strings.Builder
+	b.WriteString(ENV_PREFIX)
+
+	for i, r := range field {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// Origins lists every config field with its effective value and origin.
+func (rawCfg *ConfigRaw) Origins() []FieldOrigin {
+	v := reflect.ValueOf(rawCfg).Elem()
+
+	var res []FieldOrigin
+	for _, name := range fieldNames() {
+		idx, _ := fieldIndex(name)
+
+		origin, ok := rawCfg.origins[name]
+		if !ok {
+			origin = ORIGIN_DEFAULT
+		}
+
+		value := fmt.Sprint(v.Field(idx).Interface())
+		if list, ok := v.Field(idx).Interface().([]string); ok {
+			value = strings.Join(list, ", ")
+		}
+
+		res = append(res, FieldOrigin{
+			Field:  name,
+			Value:  value,
+			Origin: origin,
+		})
+	}
+	return res
+}
+
+func (rawCfg *ConfigRaw) setOrigin(key, origin string) {
+	if rawCfg.origins == nil {
+		rawCfg.origins = map[string]string{}
+	}
+	rawCfg.origins[key] = origin
+}
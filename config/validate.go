@@ -0,0 +1,185 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldError describes a problem with a single config field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationErrors lists every problem found in a config.
+type ValidationErrors []FieldError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (errs *ValidationErrors) add(field string, err error) {
+	if err != nil {
+		*errs = append(*errs, FieldError{Field: field, Message: err.Error()})
+	}
+}
+
+func (errs *ValidationErrors) addf(field, format string, args ...any) {
+	*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (rawCfg *ConfigRaw) validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if rawCfg.Laps < 1 {
+		errs.addf("laps", "must be >= 1, got %d", rawCfg.Laps)
+	}
+	if rawCfg.LapLen < 1 {
+		errs.addf("lapLen", "must be >= 1, got %d", rawCfg.LapLen)
+	}
+	if rawCfg.PenaltyLen < 1 {
+		errs.addf("penaltyLen", "must be >= 1, got %d", rawCfg.PenaltyLen)
+	}
+	if rawCfg.FiringLines < 1 {
+		errs.addf("firingLines", "must be >= 1, got %d", rawCfg.FiringLines)
+	}
+	if rawCfg.FiringLines > rawCfg.Laps && rawCfg.Laps >= 1 {
+		errs.addf("firingLines", "must be <= laps (%d), got %d", rawCfg.Laps, rawCfg.FiringLines)
+	}
+	if rawCfg.Start == "" {
+		errs.addf("start", "is required")
+	}
+	if rawCfg.StartDelta == "" {
+		errs.addf("startDelta", "is required")
+	}
+	for _, field := range rawCfg.unknownFields {
+		errs.addf(field, "is not a known field")
+	}
+
+	return errs
+}
+
+// decodeJSON decodes a JSON config, recording unknown fields and turning
+// decoder errors into messages pointing at the offending field or line.
+// Only keys matching a field exactly are decoded; encoding/json alone would
+// also accept them in any case.
+func decodeJSON(raw []byte) (*ConfigRaw, error) {
+	var fields map[string]json.RawMessage
+
+	err := json.Unmarshal(raw, &fields)
+
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := position(raw, syntaxErr.Offset)
+		return nil, fmt.Errorf("error while parsing json: line %d, column %d: %v", line, col, err)
+
+	case err != nil:
+		return nil, fmt.Errorf("error while parsing json: %v", err)
+	}
+
+	known := make(map[string]json.RawMessage, len(fields))
+	for key, value := range fields {
+		if _, ok := fieldIndex(key); ok {
+			known[key] = value
+		}
+	}
+
+	knownRaw, err := json.Marshal(known)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing json: %v", err)
+	}
+
+	var rawCfg ConfigRaw
+	err = json.Unmarshal(knownRaw, &rawCfg)
+
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &typeErr):
+		return nil, ValidationErrors{{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("must be %s, got %s", typeErr.Type, typeErr.Value),
+		}}
+
+	case err != nil:
+		return nil, fmt.Errorf("error while parsing json: %v", err)
+	}
+
+	rawCfg.unknownFields = unknownKeys(fields)
+	rawCfg.fileKeys = keys(fields)
+
+	return &rawCfg, nil
+}
+
+// unknownKeys returns the sorted keys that are not config fields.
+func unknownKeys[V any](fields map[string]V) []string {
+	var unknown []string
+	for key := range fields {
+		if _, ok := fieldIndex(key); !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func keys[V any](fields map[string]V) []string {
+	var res []string
+	for key := range fields {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// fieldNames returns the config field names (json tags) in declaration order.
+func fieldNames() []string {
+	var names []string
+	t := reflect.TypeOf(ConfigRaw{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// fieldIndex returns the struct field index of the config field name.
+func fieldIndex(name string) (int, bool) {
+	t := reflect.TypeOf(ConfigRaw{})
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if tag != "" && tag == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func position(raw []byte, offset int64) (int, int) {
+	line, col := 1, 1
+	for _, b := range raw[:min(int(offset), len(raw))] {
+		if b == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"biathlon/event"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const (
+	CONVERT_TEXT   = "text"
+	CONVERT_BINARY = "binary"
+)
+
+// runConvert converts an events file between the text and the binary
+// format. Without -to the other format than the input's is written.
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	to := fs.String("to", "", "output format: text or binary (default: the other format)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: convert [-to text|binary] <input_events_path> <output_events_path>")
+	}
+	in, out := fs.Arg(0), fs.Arg(1)
+
+	format := *to
+	if format == "" {
+		binary, err := isBinaryFile(in)
+		if err != nil {
+			return err
+		}
+		format = CONVERT_BINARY
+		if binary {
+			format = CONVERT_TEXT
+		}
+	}
+
+	evs, err := event.LoadEvents(in)
+	if err != nil {
+		return fmt.Errorf("error loading events: %v", err)
+	}
+
+	switch format {
+	case CONVERT_TEXT:
+		err = event.WriteEventsFile(out, evs)
+	case CONVERT_BINARY:
+		err = event.WriteBinaryEventsFile(out, evs)
+	default:
+		return fmt.Errorf("unknown format %q, expected %s or %s", format, CONVERT_TEXT, CONVERT_BINARY)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing events: %v", err)
+	}
+
+	fmt.Printf("Converted %d events to %s\n", len(evs), format)
+	return nil
+}
+
+func isBinaryFile(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	head := make([]byte, len(event.BINARY_MAGIC))
+	n, _ := file.Read(head)
+	return event.IsBinary(head[:n]), nil
+}
@@ -0,0 +1,253 @@
+package corrections
+
+import (
+	"biathlon/config"
+	"biathlon/event"
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ACTION_ADD        = "add"
+	ACTION_REMOVE     = "remove"
+	ACTION_AMEND      = "amend"
+	ACTION_PENALTY    = "penalty"
+	ACTION_DISQUALIFY = "disqualify"
+)
+
+const AMEND_SEPARATOR = "->"
+
+// Correction is a single line of a corrections file:
+//
+//	add        [hh:mm:ss.mmm] id comp [params]
+//	remove     [hh:mm:ss.mmm] id comp [params]
+//	amend      [hh:mm:ss.mmm] id comp [params] -> [hh:mm:ss.mmm] id comp [params]
+//	penalty    [hh:mm:ss.mmm] comp duration reason
+//	disqualify [hh:mm:ss.mmm] comp reason
+type Correction struct {
+	Line        int
+	Action      string
+	Event       *event.Event
+	Replacement *event.Event
+	Text        string
+}
+
+// AuditEntry records a change made by a correction. The log line is built
+// from it by the caller, which translates What.
+type AuditEntry struct {
+	Time time.Time
+	Line int
+	// What describes the change, e.g. "event added"
+	What string
+	Text string
+}
+
+func ParseCorrection(line string) (*Correction, error) {
+	action, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+
+	c := &Correction{Action: action, Text: line}
+
+	switch action {
+	case ACTION_ADD, ACTION_REMOVE:
+		e, err := event.ParseEvent(rest)
+		if err != nil {
+			return nil, err
+		}
+		c.Event = e
+
+	case ACTION_AMEND:
+		oldStr, newStr, found := strings.Cut(rest, AMEND_SEPARATOR)
+		if !found {
+			return nil, fmt.Errorf("amend requires '%s' between old and new event", AMEND_SEPARATOR)
+		}
+
+		oldEv, err := event.ParseEvent(strings.TrimSpace(oldStr))
+		if err != nil {
+			return nil, err
+		}
+		newEv, err := event.ParseEvent(strings.TrimSpace(newStr))
+		if err != nil {
+			return nil, err
+		}
+		c.Event, c.Replacement = oldEv, newEv
+
+	case ACTION_PENALTY:
+		e, err := parseDecision(rest, event.EVENT_TIME_PENALTY)
+		if err != nil {
+			return nil, err
+		}
+		c.Event = e
+
+	case ACTION_DISQUALIFY:
+		e, err := parseDecision(rest, event.EVENT_DISQUALIFIED)
+		if err != nil {
+			return nil, err
+		}
+		c.Event = e
+
+	default:
+		return nil, fmt.Errorf("unknown action: %s", action)
+	}
+
+	return c, nil
+}
+
+// parseDecision parses "[time] comp params..." into an event with the given
+// ID, which validates its parameters.
+func parseDecision(s string, eventID int) (*event.Event, error) {
+	endIdx := strings.Index(s, "]")
+	if !strings.HasPrefix(s, "[") || endIdx < 0 {
+		return nil, fmt.Errorf("missing time")
+	}
+
+	tokens := strings.Fields(s[endIdx+1:])
+	if len(tokens) < 1 {
+		return nil, fmt.Errorf("missing competitor ID")
+	}
+
+	line := fmt.Sprintf("%s %d %s", s[:endIdx+1], eventID, strings.Join(tokens, " "))
+	return event.ParseEvent(line)
+}
+
+func LoadCorrections(filename string) ([]*Correction, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var corrections []*Correction
+	scanner := bufio.NewScanner(file)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		c, err := ParseCorrection(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: failed to parse '%s': %v", lineNum, line, err)
+		}
+		c.Line = lineNum
+		corrections = append(corrections, c)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return corrections, nil
+}
+
+// ResolveDates places corrections without a date on the day of ref, usually
+// the race start, see event.ResolveDates.
+func ResolveDates(corrections []*Correction, ref time.Time) {
+	for _, c := range corrections {
+		for _, e := range []*event.Event{c.Event, c.Replacement} {
+			switch {
+			case e == nil:
+			case e.Dated:
+				e.Time = config.InLocation(e.Time, ref.Location())
+			default:
+				e.Time = config.AnchorClock(ref, e.Time)
+			}
+		}
+	}
+}
+
+// Apply returns a copy of events with corrections applied in order, and an
+// audit trail describing every change. Events stay ordered by time.
+func Apply(events []*event.Event, corrections []*Correction) ([]*event.Event, []AuditEntry, error) {
+	result := make([]*event.Event, len(events))
+	copy(result, events)
+
+	var audit []AuditEntry
+
+	for _, c := range corrections {
+		switch c.Action {
+		case ACTION_ADD:
+			result = insertSorted(result, c.Event)
+			audit = append(audit, c.audit(c.Event.Time, "event added"))
+
+		case ACTION_REMOVE:
+			idx := find(result, c.Event)
+			if idx < 0 {
+				return nil, nil, fmt.Errorf("line %d: event to remove not found", c.Line)
+			}
+			result = append(result[:idx], result[idx+1:]...)
+			audit = append(audit, c.audit(c.Event.Time, "event removed"))
+
+		case ACTION_AMEND:
+			idx := find(result, c.Event)
+			if idx < 0 {
+				return nil, nil, fmt.Errorf("line %d: event to amend not found", c.Line)
+			}
+			result = append(result[:idx], result[idx+1:]...)
+			result = insertSorted(result, c.Replacement)
+			audit = append(audit, c.audit(c.Replacement.Time, "event amended"))
+
+		case ACTION_PENALTY:
+			result = insertSorted(result, c.Event)
+			audit = append(audit, c.audit(c.Event.Time, "time penalty added"))
+
+		case ACTION_DISQUALIFY:
+			result = insertSorted(result, c.Event)
+			audit = append(audit, c.audit(c.Event.Time, "competitor disqualified"))
+		}
+	}
+
+	return result, audit, nil
+}
+
+func (c *Correction) audit(t time.Time, what string) AuditEntry {
+	return AuditEntry{
+		Time: t,
+		Line: c.Line,
+		What: what,
+		Text: c.Text,
+	}
+}
+
+// insertSorted inserts e after all events with the same or earlier time.
+func insertSorted(events []*event.Event, e *event.Event) []*event.Event {
+	idx := sort.Search(len(events), func(i int) bool {
+		return events[i].Time.After(e.Time)
+	})
+
+	events = append(events, nil)
+	copy(events[idx+1:], events[idx:])
+	events[idx] = e
+	return events
+}
+
+func find(events []*event.Event, target *event.Event) int {
+	for i, e := range events {
+		if sameEvent(e, target) {
+			return i
+		}
+	}
+	return -1
+}
+
+func sameEvent(a, b *event.Event) bool {
+	if !a.Time.Equal(b.Time) || a.EventID != b.EventID || a.CompetitorID != b.CompetitorID {
+		return false
+	}
+	if len(a.ExtraParams) != len(b.ExtraParams) {
+		return false
+	}
+	for i := range a.ExtraParams {
+		if a.ExtraParams[i] != b.ExtraParams[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package corrections
+
+import (
+	"biathlon/event"
+	"testing"
+	"time"
+)
+
+func at(h, m, s int) time.Time {
+	return time.Date(0, 1, 1, h, m, s, 0, time.UTC)
+}
+
+func testEvents() []*event.Event {
+	return []*event.Event{
+		{Time: at(10, 0, 0), EventID: 4, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: at(10, 5, 0), EventID: 5, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: at(10, 5, 2), EventID: 6, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: at(10, 5, 30), EventID: 7, CompetitorID: 1, ExtraParams: []string{}},
+	}
+}
+
+func mustParse(t *testing.T, line string) *Correction {
+	t.Helper()
+
+	c, err := ParseCorrection(line)
+	if err != nil {
+		t.Fatalf("ParseCorrection(%q) error = %v", line, err)
+	}
+	return c
+}
+
+func TestParseCorrection_Invalid(t *testing.T) {
+	tests := []string{
+		"replace [10:00:00.000] 1 1",
+		"amend [10:00:00.000] 6 1 1",
+		"penalty [10:00:00.000] 1 soon",
+		"penalty [10:00:00.000] 1",
+		"disqualify 1 reason",
+	}
+
+	for _, line := range tests {
+		t.Run(line, func(t *testing.T) {
+			if _, err := ParseCorrection(line); err == nil {
+				t.Errorf("Expected error for %q, got nil", line)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	evs := testEvents()
+	corrs := []*Correction{
+		mustParse(t, "add [10:05:03.000] 6 1 2"),
+		mustParse(t, "remove [10:05:30.000] 7 1"),
+		mustParse(t, "amend [10:05:02.000] 6 1 1 -> [10:05:01.000] 6 1 3"),
+		mustParse(t, "penalty [10:20:00.000] 1 00:01:00 Missed target"),
+	}
+
+	got, audit, err := Apply(evs, corrs)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if len(audit) != len(corrs) {
+		t.Errorf("Expected %d audit entries, got %d", len(corrs), len(audit))
+	}
+
+	want := []struct {
+		id     int
+		target string
+	}{
+		{4, ""}, {5, "1"}, {6, "3"}, {6, "2"}, {event.EVENT_TIME_PENALTY, "00:01:00"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d events, got %d", len(want), len(got))
+	}
+	for i, w := range want {
+		if got[i].EventID != w.id {
+			t.Errorf("Event %d: expected ID %d, got %d", i, w.id, got[i].EventID)
+		}
+		if w.target != "" && got[i].ExtraParams[0] != w.target {
+			t.Errorf("Event %d: expected param %s, got %v", i, w.target, got[i].ExtraParams)
+		}
+	}
+
+	if len(evs) != 4 || evs[3].EventID != 7 {
+		t.Error("Expected original events to be left untouched")
+	}
+}
+
+func TestApply_NotFound(t *testing.T) {
+	corrs := []*Correction{mustParse(t, "remove [11:00:00.000] 7 1")}
+
+	if _, _, err := Apply(testEvents(), corrs); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
+
+func TestLoadCorrections_FileNotFound(t *testing.T) {
+	if _, err := LoadCorrections("nonexistent_corrections"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/corrections"
+	"biathlon/event"
+	"biathlon/i18n"
+	"biathlon/processor"
+	"biathlon/storage"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+const dbUsage = `usage:
+  db import -db <path> -race <name> <config_path> <events_path>
+  db append -db <path> -race <name> [-corrections <path>] [<events_path>]
+  db show   -db <path> -race <name>
+  db list   -db <path>`
+
+func runDB(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("%s", dbUsage)
+	}
+
+	fs := flag.NewFlagSet("db "+args[0], flag.ContinueOnError)
+	dbPath := fs.String("db", "races.db", "path to the race database")
+	race := fs.String("race", "", "race name")
+	corrPath := fs.String("corrections", "", "corrections file to append")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	store, err := storage.Open(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	switch args[0] {
+	case "import":
+		if *race == "" || fs.NArg() != 2 {
+			return fmt.Errorf("%s", dbUsage)
+		}
+		return dbImport(store, *race, fs.Arg(0), fs.Arg(1))
+
+	case "append":
+		if *race == "" || fs.NArg() > 1 || (fs.NArg() == 0 && *corrPath == "") {
+			return fmt.Errorf("%s", dbUsage)
+		}
+		return dbAppend(store, *race, fs.Arg(0), *corrPath)
+
+	case "show":
+		if *race == "" {
+			return fmt.Errorf("%s", dbUsage)
+		}
+		return dbShow(store, *race)
+
+	case "list":
+		return dbList(store)
+	}
+
+	return fmt.Errorf("%s", dbUsage)
+}
+
+func dbImport(store *storage.Store, race, cfgPath, evsPath string) error {
+	raw, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %v", err)
+	}
+
+	rawCfg, err := config.DecodeRaw(raw, config.FormatFromPath(cfgPath))
+	if err != nil {
+		return fmt.Errorf("error loading config: %v", err)
+	}
+
+	cfg, err := rawCfg.Build()
+	if err != nil {
+		return fmt.Errorf("error loading config: %v", err)
+	}
+
+	// The database keeps configs as JSON whatever format they came in
+	cfgJSON, err := json.Marshal(rawCfg)
+	if err != nil {
+		return err
+	}
+
+	evs, err := event.LoadEvents(evsPath)
+	if err != nil {
+		return fmt.Errorf("error loading events: %v", err)
+	}
+	event.ResolveDates(evs, cfg.Start)
+
+	if _, err := store.CreateRace(race, cfgJSON, evs); err != nil {
+		return err
+	}
+	return recompute(store, race)
+}
+
+// dbAppend adds events and/or corrections to a stored race. Corrections
+// that don't apply to the race are rejected before anything is stored.
+func dbAppend(store *storage.Store, race, evsPath, corrPath string) error {
+	cfg, err := store.Config(race)
+	if err != nil {
+		return err
+	}
+
+	var evs []*event.Event
+	if evsPath != "" {
+		evs, err = event.LoadEvents(evsPath)
+		if err != nil {
+			return fmt.Errorf("error loading events: %v", err)
+		}
+		event.ResolveDates(evs, cfg.Start)
+	}
+
+	var corrs []*corrections.Correction
+	if corrPath != "" {
+		corrs, err = corrections.LoadCorrections(corrPath)
+		if err != nil {
+			return fmt.Errorf("error loading corrections: %v", err)
+		}
+		if err := checkCorrections(store, race, cfg, evs, corrs); err != nil {
+			return err
+		}
+	}
+
+	if err := store.AppendEvents(race, evs); err != nil {
+		return err
+	}
+	if err := store.AppendCorrections(race, corrs); err != nil {
+		return err
+	}
+	return recompute(store, race)
+}
+
+// checkCorrections applies the stored and the new corrections to the stored
+// and the new events.
+func checkCorrections(store *storage.Store, race string, cfg *config.Config, evs []*event.Event, corrs []*corrections.Correction) error {
+	stored, err := store.Events(race)
+	if err != nil {
+		return err
+	}
+	all := append(stored, evs...)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Time.Before(all[j].Time)
+	})
+
+	storedCorrs, err := store.Corrections(race)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = applyCorrections(all, append(storedCorrs, corrs...), cfg, i18n.English)
+	return err
+}
+
+func dbShow(store *storage.Store, race string) error {
+	logs, err := store.Output(race, storage.KIND_LOG)
+	if err != nil {
+		return err
+	}
+
+	results, err := store.Output(race, storage.KIND_RESULT)
+	if err != nil {
+		return err
+	}
+
+	printOutput(logs, results)
+	return nil
+}
+
+func dbList(store *storage.Store) error {
+	races, err := store.Races()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range races {
+		fmt.Printf("%s\t%s\n", r.Name, r.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+// recompute processes the stored events of a race and saves the output.
+func recompute(store *storage.Store, race string) error {
+	cfg, err := store.Config(race)
+	if err != nil {
+		return err
+	}
+
+	evs, err := store.Events(race)
+	if err != nil {
+		return err
+	}
+
+	corrs, err := store.Corrections(race)
+	if err != nil {
+		return err
+	}
+
+	evs, notes, err := applyCorrections(evs, corrs, cfg, i18n.English)
+	if err != nil {
+		return err
+	}
+
+	logs, results := processRace(processor.NewProcessor(cfg, evs), notes...)
+	if err := store.SaveOutput(race, logs, results); err != nil {
+		return err
+	}
+
+	printOutput(logs, results)
+	return nil
+}
@@ -0,0 +1,253 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// BINARY_MAGIC starts every binary event file. It is followed by records,
+// each a uvarint length and then:
+//
+//	flags       byte (bit 0: dated)
+//	time        varint milliseconds since the previous record (since the
+//	            Unix epoch for the first one)
+//	event ID    uvarint
+//	competitor  uvarint
+//	params      uvarint count, then a uvarint length and bytes each
+//
+// Times are stored in UTC with millisecond precision.
+const BINARY_MAGIC = "BIEV\x01"
+
+// MAX_RECORD_LEN bounds the length of a binary record, so a corrupt length
+// can't make the reader allocate huge buffers.
+const MAX_RECORD_LEN = 1 << 16
+
+const flagDated = 1 << 0
+
+// Reader yields events one at a time and returns io.EOF after the last one.
+type Reader interface {
+	Next() (*Event, error)
+}
+
+// IsBinary reports whether data starts like a binary event file.
+func IsBinary(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(BINARY_MAGIC))
+}
+
+type BinaryWriter struct {
+	w      *bufio.Writer
+	prevMs int64
+	buf    []byte
+}
+
+// NewBinaryWriter writes the file header and returns a writer for records.
+// Call Flush when done.
+func NewBinaryWriter(w io.Writer) (*BinaryWriter, error) {
+	bw := &BinaryWriter{w: bufio.NewWriter(w)}
+	if _, err := bw.w.WriteString(BINARY_MAGIC); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	return bw, nil
+}
+
+func (bw *BinaryWriter) Write(e *Event) error {
+	if e.EventID < 0 || e.CompetitorID < 0 {
+		return fmt.Errorf("event %d of competitor %d: negative IDs can't be encoded", e.EventID, e.CompetitorID)
+	}
+
+	ms := e.Time.UnixMilli()
+
+	var flags byte
+	if e.Dated {
+		flags |= flagDated
+	}
+
+	rec := bw.buf[:0]
+	rec = append(rec, flags)
+	rec = binary.AppendVarint(rec, ms-bw.prevMs)
+	rec = binary.AppendUvarint(rec, uint64(e.EventID))
+	rec = binary.AppendUvarint(rec, uint64(e.CompetitorID))
+
+	params := e.params()
+	rec = binary.AppendUvarint(rec, uint64(len(params)))
+	for _, p := range params {
+		rec = binary.AppendUvarint(rec, uint64(len(p)))
+		rec = append(rec, p...)
+	}
+	bw.buf = rec
+
+	if len(rec) > MAX_RECORD_LEN {
+		return fmt.Errorf("event %d of competitor %d: record of %d bytes exceeds %d", e.EventID, e.CompetitorID, len(rec), MAX_RECORD_LEN)
+	}
+
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(rec)))
+	if _, err := bw.w.Write(length[:n]); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	if _, err := bw.w.Write(rec); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	bw.prevMs = ms
+	return nil
+}
+
+func (bw *BinaryWriter) Flush() error {
+	return bw.w.Flush()
+}
+
+type BinaryReader struct {
+	r      *bufio.Reader
+	prevMs int64
+	buf    []byte
+	record int
+}
+
+// NewBinaryReader checks the file header and returns a reader for records.
+func NewBinaryReader(r io.Reader) (*BinaryReader, error) {
+	br := &BinaryReader{r: bufio.NewReader(r)}
+
+	header := make([]byte, len(BINARY_MAGIC))
+	if _, err := io.ReadFull(br.r, header); err != nil || !IsBinary(header) {
+		return nil, fmt.Errorf("not a binary event file")
+	}
+	return br, nil
+}
+
+// Next decodes the next record, validating its parameters like ParseEvent.
+func (br *BinaryReader) Next() (*Event, error) {
+	length, err := binary.ReadUvarint(br.r)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	br.record++
+	if err != nil {
+		return nil, br.errorf("invalid record length: %v", err)
+	}
+	if length > MAX_RECORD_LEN {
+		return nil, br.errorf("record length %d exceeds %d", length, MAX_RECORD_LEN)
+	}
+
+	if uint64(cap(br.buf)) < length {
+		br.buf = make([]byte, length)
+	}
+	rec := br.buf[:length]
+	if _, err := io.ReadFull(br.r, rec); err != nil {
+		return nil, br.errorf("truncated record: %v", err)
+	}
+
+	e, err := br.decode(rec)
+	if err != nil {
+		return nil, br.errorf("%v", err)
+	}
+	return e, nil
+}
+
+func (br *BinaryReader) errorf(format string, args ...any) error {
+	return fmt.Errorf("record %d: %s", br.record, fmt.Sprintf(format, args...))
+}
+
+var errShortRecord = errors.New("record too short")
+
+func (br *BinaryReader) decode(rec []byte) (*Event, error) {
+	if len(rec) < 1 {
+		return nil, errShortRecord
+	}
+	flags := rec[0]
+	rec = rec[1:]
+
+	delta, n := binary.Varint(rec)
+	if n <= 0 {
+		return nil, errShortRecord
+	}
+	rec = rec[n:]
+
+	var fields [3]uint64
+	for i := range fields {
+		v, n := binary.Uvarint(rec)
+		if n <= 0 {
+			return nil, errShortRecord
+		}
+		fields[i] = v
+		rec = rec[n:]
+	}
+
+	// Every parameter takes at least its length byte
+	if fields[2] > uint64(len(rec)) {
+		return nil, errShortRecord
+	}
+	params := make([]string, 0, fields[2])
+	for range fields[2] {
+		size, n := binary.Uvarint(rec)
+		if n <= 0 || uint64(len(rec)-n) < size {
+			return nil, errShortRecord
+		}
+		params = append(params, string(rec[n:n+int(size)]))
+		rec = rec[n+int(size):]
+	}
+
+	ms := br.prevMs + delta
+	br.prevMs = ms
+
+	e := &Event{
+		Time:         time.UnixMilli(ms).UTC(),
+		EventID:      int(fields[0]),
+		CompetitorID: int(fields[1]),
+		ExtraParams:  params,
+		Dated:        flags&flagDated != 0,
+	}
+
+	payload, err := ParsePayload(e.EventID, params)
+	if err != nil {
+		return nil, err
+	}
+	e.Payload = payload
+	return e, nil
+}
+
+// ReadAll reads the remaining events.
+func ReadAll(r Reader) ([]*Event, error) {
+	var events []*Event
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+}
+
+// WriteBinaryEvents writes events in the binary format.
+func WriteBinaryEvents(w io.Writer, events []*Event) error {
+	bw, err := NewBinaryWriter(w)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		if err := bw.Write(e); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func WriteBinaryEventsFile(path string, events []*Event) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	return WriteBinaryEvents(file, events)
+}
@@ -0,0 +1,137 @@
+package event
+
+import (
+	"biathlon/config"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String renders the event as its canonical events file line,
+// [hh:mm:ss.mmm] id comp params. Dated events keep their date.
+func (e *Event) String() string {
+	format := config.TIME_FORMAT_WITH_MS
+	if e.Dated {
+		format = config.DATETIME_FORMAT_WITH_MS
+	}
+
+	var b strings.Builder
+	b.WriteByte('[')
+	b.WriteString(e.Time.Format(format))
+	b.WriteString("] ")
+	b.WriteString(strconv.Itoa(e.EventID))
+	b.WriteByte(' ')
+	b.WriteString(strconv.Itoa(e.CompetitorID))
+	for _, param := range e.params() {
+		b.WriteByte(' ')
+		b.WriteString(param)
+	}
+	return b.String()
+}
+
+// params returns the raw parameters, rendering them from the payload for
+// events built in code without ExtraParams.
+func (e *Event) params() []string {
+	if len(e.ExtraParams) == 0 && e.Payload != nil {
+		return e.Payload.Params()
+	}
+	return e.ExtraParams
+}
+
+func (e *Event) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText parses a single events file line.
+func (e *Event) UnmarshalText(text []byte) error {
+	parsed, err := ParseEvent(strings.TrimSpace(string(text)))
+	if err != nil {
+		return err
+	}
+	*e = *parsed
+	return nil
+}
+
+// eventJSON is the JSON form of an event with an ISO-8601 time.
+type eventJSON struct {
+	Time         time.Time `json:"time"`
+	Dated        bool      `json:"dated,omitempty"`
+	EventID      int       `json:"eventId"`
+	CompetitorID int       `json:"competitorId"`
+	Params       []string  `json:"params"`
+	Source       string    `json:"source,omitempty"`
+}
+
+func (e *Event) MarshalJSON() ([]byte, error) {
+	params := e.params()
+	if params == nil {
+		params = []string{}
+	}
+
+	return json.Marshal(eventJSON{
+		Time:         e.Time,
+		Dated:        e.Dated,
+		EventID:      e.EventID,
+		CompetitorID: e.CompetitorID,
+		Params:       params,
+		Source:       e.Source,
+	})
+}
+
+// UnmarshalJSON decodes an event and validates its parameters like
+// ParseEvent.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var raw eventJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	params := raw.Params
+	if params == nil {
+		params = []string{}
+	}
+
+	payload, err := ParsePayload(raw.EventID, params)
+	if err != nil {
+		return err
+	}
+
+	*e = Event{
+		Time:         raw.Time,
+		EventID:      raw.EventID,
+		CompetitorID: raw.CompetitorID,
+		ExtraParams:  params,
+		Source:       raw.Source,
+		Dated:        raw.Dated,
+		Payload:      payload,
+	}
+	return nil
+}
+
+// WriteEvents writes events in the events file format, one line each.
+func WriteEvents(w io.Writer, events []*Event) error {
+	writer := bufio.NewWriter(w)
+
+	for _, e := range events {
+		if _, err := writer.WriteString(e.String() + "\n"); err != nil {
+			return fmt.Errorf("failed to write event: %w", err)
+		}
+	}
+
+	return writer.Flush()
+}
+
+func WriteEventsFile(path string, events []*Event) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	return WriteEvents(file, events)
+}
@@ -2,9 +2,9 @@ package event
 
 import (
 	"biathlon/config"
-	"bufio"
 	"fmt"
-	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,74 +15,300 @@ type Event struct {
 	EventID      int
 	CompetitorID int
 	ExtraParams  []string
+	Source       string
+	// Dated is set when the event line carried a full date, not only a clock time
+	Dated bool
+	// Payload holds the typed parameters of built-in event types
+	Payload Payload
+}
+
+var dateTimeFormats = []string{
+	config.DATETIME_FORMAT_WITH_MS,
+	"2006-01-02T15:04:05.000",
+}
+
+// ParseTime parses a clock time (hh:mm:ss.mmm) or a full date-time
+// (yyyy-mm-dd hh:mm:ss.mmm or yyyy-mm-ddThh:mm:ss.mmm) and reports
+// whether a date was present.
+func ParseTime(s string) (time.Time, bool, error) {
+	if t, ok := parseClock(s); ok {
+		return t, false, nil
+	}
+
+	t, err := time.Parse(config.TIME_FORMAT_WITH_MS, s)
+	if err == nil {
+		return t, false, nil
+	}
+
+	for _, format := range dateTimeFormats {
+		if t, dtErr := time.Parse(format, s); dtErr == nil {
+			return t, true, nil
+		}
+	}
+	return t, false, err
+}
+
+// parseClock is a fast path for the hh:mm:ss.mmm clock times of event
+// lines, returning the same time as time.Parse.
+func parseClock(s string) (time.Time, bool) {
+	if len(s) != len(config.TIME_FORMAT_WITH_MS) || s[2] != ':' || s[5] != ':' || s[8] != '.' {
+		return time.Time{}, false
+	}
+
+	num := func(from, to int) int {
+		n := 0
+		for i := from; i < to; i++ {
+			c := s[i]
+			if c < '0' || c > '9' {
+				return -1
+			}
+			n = n*10 + int(c-'0')
+		}
+		return n
+	}
+
+	h, m, sec, ms := num(0, 2), num(3, 5), num(6, 8), num(9, 12)
+	if h < 0 || h > 23 || m < 0 || m > 59 || sec < 0 || sec > 59 || ms < 0 {
+		return time.Time{}, false
+	}
+	return time.Date(0, 1, 1, h, m, sec, ms*int(time.Millisecond), time.UTC), true
 }
 
 func ParseEvent(line string) (*Event, error) {
+	e := &Event{}
+	if err := ParseEventInto(line, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
 
-	endIdx := strings.Index(line, "]")
-	timeStr := line[1:endIdx]
-	eventPart := strings.TrimSpace(line[endIdx+1:])
+// ParseEventInto parses a line into an existing event, reusing its
+// ExtraParams storage. Source is left untouched.
+func ParseEventInto(line string, e *Event) error {
+	if len(line) == 0 || line[0] != '[' {
+		return fmt.Errorf("expected line to start with [time]")
+	}
+	endIdx := strings.IndexByte(line, ']')
+	if endIdx < 0 {
+		return fmt.Errorf("missing closing bracket after time")
+	}
 
-	parsedTime, err := time.Parse(config.TIME_FORMAT_WITH_MS, timeStr)
+	parsedTime, dated, err := ParseTime(line[1:endIdx])
 	if err != nil {
-		return nil, fmt.Errorf("invalid time format: %v", err)
+		return fmt.Errorf("invalid time format: %v", err)
 	}
 
-	tokens := strings.Fields(eventPart)
-	if len(tokens) < 2 {
-		return nil, fmt.Errorf("not enough tokens: %v", err)
+	idStr, rest := nextField(line[endIdx+1:])
+	compStr, rest := nextField(rest)
+	if compStr == "" {
+		return fmt.Errorf("not enough tokens: expected event ID and competitor ID")
 	}
 
-	eventID, err := strconv.Atoi(tokens[0])
+	eventID, err := strconv.Atoi(idStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid event ID: %s", tokens[0])
+		return fmt.Errorf("invalid event ID: %s", idStr)
 	}
 
-	competitorID, err := strconv.Atoi(tokens[1])
+	competitorID, err := strconv.Atoi(compStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid competitor ID: %s", tokens[1])
+		return fmt.Errorf("invalid competitor ID: %s", compStr)
 	}
 
-	extra := []string{}
-	if len(tokens) > 2 {
-		extra = tokens[2:]
+	extra := e.ExtraParams[:0]
+	for {
+		var param string
+		param, rest = nextField(rest)
+		if param == "" {
+			break
+		}
+		extra = append(extra, param)
 	}
 
-	return &Event{
-		Time:         parsedTime,
-		EventID:      eventID,
-		CompetitorID: competitorID,
-		ExtraParams:  extra,
-	}, nil
+	payload, err := ParsePayload(eventID, extra)
+	if err != nil {
+		return err
+	}
+
+	e.Time = parsedTime
+	e.EventID = eventID
+	e.CompetitorID = competitorID
+	e.ExtraParams = extra
+	e.Dated = dated
+	e.Payload = payload
+	return nil
+}
 
+// nextField returns the first space-separated field of s and the rest of
+// s after it, without allocating.
+func nextField(s string) (string, string) {
+	i := 0
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	start := i
+	for i < len(s) && s[i] != ' ' && s[i] != '\t' {
+		i++
+	}
+	return s[start:i], s[i:]
 }
 
+// LoadEvents reads an events file in the text or the binary format.
 func LoadEvents(filename string) ([]*Event, error) {
-	file, err := os.Open(filename)
+	f, err := OpenEvents(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	defer f.Close()
 
-	var events []*Event
-	scanner := bufio.NewScanner(file)
+	return ReadAll(f)
+}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
+// LoadEventSources loads every file matching the given paths or glob
+// patterns. Each file becomes a separate source, in the order given.
+func LoadEventSources(patterns ...string) ([][]*Event, error) {
+	var sources [][]*Event
 
-		event, err := ParseEvent(line)
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(pattern)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse line '%s': %v", line, err)
+			return nil, fmt.Errorf("invalid pattern '%s': %v", pattern, err)
 		}
-		events = append(events, event)
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no event files match '%s'", pattern)
+		}
+
+		for _, path := range paths {
+			events, err := LoadEvents(path)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, events)
+		}
+	}
+
+	return sources, nil
+}
+
+// ResolveDates places events without a date on the day of ref, usually the
+// race start, on the day closest to the previous event: a clock time more
+// than ROLLOVER_THRESHOLD before it moves to the next day, one more than
+// ROLLOVER_THRESHOLD after it to the previous day. Dated
+// events keep their date and become the reference for the events that
+// follow them. All times are moved to the time zone of ref. Events must be
+// in file order.
+func ResolveDates(events []*Event, ref time.Time) {
+	for _, e := range events {
+		resolveDate(e, ref)
+		ref = e.Time
+	}
+}
+
+func resolveDate(e *Event, ref time.Time) {
+	if e.Dated {
+		e.Time = config.InLocation(e.Time, ref.Location())
+	} else {
+		e.Time = config.AnchorClock(ref, e.Time)
 	}
+}
 
-	if err := scanner.Err(); err != nil {
+type dateResolver struct {
+	r   Reader
+	ref time.Time
+}
+
+// ResolveDatesFrom returns a Reader resolving the dates of events from r
+// like ResolveDates, one event at a time.
+func ResolveDatesFrom(r Reader, ref time.Time) Reader {
+	return &dateResolver{r: r, ref: ref}
+}
+
+func (d *dateResolver) Next() (*Event, error) {
+	e, err := d.r.Next()
+	if err != nil {
 		return nil, err
 	}
+	resolveDate(e, d.ref)
+	d.ref = e.Time
+	return e, nil
+}
+
+// NEAR_DUPLICATE_WINDOW is how close in time two otherwise identical events
+// must be to be treated as the same event reported twice.
+const NEAR_DUPLICATE_WINDOW = time.Second
+
+type Duplicate struct {
+	Event    *Event
+	Original *Event
+	Exact    bool
+}
+
+// MergeReport describes what Merge changed in the incoming event streams.
+type MergeReport struct {
+	Reordered  []*Event
+	Duplicates []Duplicate
+}
+
+// Merge combines event sources into a single stream sorted stably by time.
+// Exact duplicates and near duplicates (same event, competitor and params
+// within window) are dropped, keeping the earliest copy.
+func Merge(window time.Duration, sources ...[]*Event) ([]*Event, *MergeReport) {
+	report := &MergeReport{}
 
-	return events, nil
+	var merged []*Event
+	for _, src := range sources {
+		var latest time.Time
+		for i, e := range src {
+			if i > 0 && e.Time.Before(latest) {
+				report.Reordered = append(report.Reordered, e)
+			}
+			if i == 0 || e.Time.After(latest) {
+				latest = e.Time
+			}
+		}
+		merged = append(merged, src...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time.Before(merged[j].Time)
+	})
+
+	// Index of kept events by identity for duplicate lookup
+	kept := make(map[string][]*Event)
+	result := make([]*Event, 0, len(merged))
+
+	for _, e := range merged {
+		key := e.identity()
+
+		if orig := findNear(kept[key], e, window); orig != nil {
+			report.Duplicates = append(report.Duplicates, Duplicate{
+				Event:    e,
+				Original: orig,
+				Exact:    orig.Time.Equal(e.Time),
+			})
+			continue
+		}
+
+		kept[key] = append(kept[key], e)
+		result = append(result, e)
+	}
+
+	return result, report
+}
+
+func (e *Event) identity() string {
+	return fmt.Sprintf("%d %d %s", e.EventID, e.CompetitorID, strings.Join(e.ExtraParams, " "))
+}
+
+// findNear returns the latest candidate within window before e. Candidates
+// are ordered by time, so only the last one needs checking.
+func findNear(candidates []*Event, e *Event, window time.Duration) *Event {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	last := candidates[len(candidates)-1]
+	if e.Time.Sub(last.Time) <= window {
+		return last
+	}
+	return nil
 }
@@ -1,6 +1,13 @@
 package event
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -12,15 +19,21 @@ func TestParseEvent(t *testing.T) {
 		err      bool
 	}{
 		{
-			input: "[12:34:56.789] 1 1001 start",
+			input: "[12:34:56.789] 11 1001 start",
 			expected: &Event{
 				Time:         time.Date(0, 1, 1, 12, 34, 56, 789000000, time.UTC),
-				EventID:      1,
+				EventID:      11,
 				CompetitorID: 1001,
 				ExtraParams:  []string{"start"},
 			},
 			err: false,
 		},
+		{
+			// Registration takes no parameters
+			input:    "[12:34:56.789] 1 1001 start",
+			expected: nil,
+			err:      true,
+		},
 		{
 			input:    "[invalid time] 1 1001 start",
 			expected: nil,
@@ -61,6 +74,61 @@ func TestParseEvent(t *testing.T) {
 	}
 }
 
+func TestParseEvent_Payload(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Payload
+		err      string
+	}{
+		{"[09:30:00.000] 2 1 10:00:00.000", StartTimePayload{Time: time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)}, ""},
+		{"[09:30:00.000] 5 1 2", FiringRangePayload{Range: 2}, ""},
+		{"[09:30:00.000] 6 1 4", TargetPayload{Target: 4}, ""},
+		{"[09:30:00.000] 11 1 Lost in the forest", ReasonPayload{Text: "Lost in the forest"}, ""},
+		{"[09:30:00.000] 12 1 1m30s Skipped lap", TimePenaltyPayload{Duration: 90 * time.Second, Reason: "Skipped lap"}, ""},
+		{"[09:30:00.000] 32 1", ReasonPayload{}, ""},
+		{"[09:30:00.000] 4 1", nil, ""},
+		{"[09:30:00.000] 40 1 anything goes", nil, ""},
+		{"[09:30:00.000] 2 1 soon", nil, `event 2 (Start time): invalid start time "soon": expected time`},
+		{"[09:30:00.000] 2 1", nil, "event 2 (Start time): expected 1 parameter(s), got 0"},
+		{"[09:30:00.000] 6 1 four", nil, `event 6 (Hit): invalid target "four": expected int`},
+		{"[09:30:00.000] 5 1 1 2", nil, "event 5 (On the firing range): expected at most 1 parameter(s), got 2"},
+		{"[09:30:00.000] 12 1", nil, "event 12 (Time penalty): expected 1 parameter(s), got 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseEvent(tt.input)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEvent() error = %v", err)
+			}
+			if got.Payload != tt.expected {
+				t.Errorf("Expected payload %#v, got %#v", tt.expected, got.Payload)
+			}
+		})
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	e := &Event{EventID: EVENT_HIT, ExtraParams: []string{"3"}}
+	if err := e.DecodePayload(); err != nil {
+		t.Fatalf("DecodePayload() error = %v", err)
+	}
+	if e.Payload != (TargetPayload{Target: 3}) {
+		t.Errorf("Expected target payload, got %#v", e.Payload)
+	}
+
+	bad := &Event{EventID: EVENT_HIT}
+	if err := bad.DecodePayload(); err == nil {
+		t.Error("Expected error for missing target")
+	}
+}
+
 func equalStringSlices(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -72,3 +140,522 @@ func equalStringSlices(a, b []string) bool {
 	}
 	return true
 }
+
+func TestMerge(t *testing.T) {
+	at := func(s, ms int) time.Time {
+		return time.Date(0, 1, 1, 10, 0, s, ms*int(time.Millisecond), time.UTC)
+	}
+
+	start := []*Event{
+		{Time: at(0, 0), EventID: 4, CompetitorID: 1},
+		{Time: at(5, 0), EventID: 4, CompetitorID: 2},
+		{Time: at(3, 0), EventID: 4, CompetitorID: 3},
+	}
+	finish := []*Event{
+		{Time: at(0, 0), EventID: 4, CompetitorID: 1},
+		{Time: at(5, 400), EventID: 4, CompetitorID: 2},
+		{Time: at(10, 0), EventID: 6, CompetitorID: 1, ExtraParams: []string{"1"}},
+		{Time: at(10, 500), EventID: 6, CompetitorID: 1, ExtraParams: []string{"2"}},
+	}
+
+	got, report := Merge(NEAR_DUPLICATE_WINDOW, start, finish)
+
+	wantIDs := []int{1, 3, 2, 1, 1}
+	if len(got) != len(wantIDs) {
+		t.Fatalf("Expected %d events, got %d", len(wantIDs), len(got))
+	}
+	for i, id := range wantIDs {
+		if got[i].CompetitorID != id {
+			t.Errorf("Event %d: expected competitor %d, got %d", i, id, got[i].CompetitorID)
+		}
+	}
+
+	if len(report.Reordered) != 1 || report.Reordered[0].CompetitorID != 3 {
+		t.Errorf("Expected competitor 3 to be reported as reordered, got %v", report.Reordered)
+	}
+
+	if len(report.Duplicates) != 2 {
+		t.Fatalf("Expected 2 duplicates, got %d", len(report.Duplicates))
+	}
+	if !report.Duplicates[0].Exact || report.Duplicates[1].Exact {
+		t.Errorf("Expected one exact and one near duplicate, got %+v", report.Duplicates)
+	}
+}
+
+func TestLoadEventSources(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"start.txt":  "[10:00:00.000] 4 1\n",
+		"finish.txt": "[10:30:00.000] 10 1\n",
+		"broken.log": "[10:00:00.000] 4\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	sources, err := LoadEventSources(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		t.Fatalf("LoadEventSources() error = %v", err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("Expected 2 sources, got %d", len(sources))
+	}
+	if sources[0][0].Source != filepath.Join(dir, "finish.txt") {
+		t.Errorf("Expected source finish.txt, got %s", sources[0][0].Source)
+	}
+
+	_, err = LoadEventSources(filepath.Join(dir, "broken.log"))
+	if err == nil || !strings.Contains(err.Error(), "broken.log:1") {
+		t.Errorf("Expected error naming broken.log:1, got %v", err)
+	}
+
+	if _, err := LoadEventSources(filepath.Join(dir, "missing*")); err == nil {
+		t.Error("Expected error for pattern without matches, got nil")
+	}
+}
+
+func TestResolveDates(t *testing.T) {
+	lines := []string{
+		"[23:59:58.000] 4 1",
+		"[23:59:59.500] 5 1 1",
+		"[00:00:01.000] 6 1 1",
+		"[2026-01-02 00:00:02.000] 7 1",
+		"[23:59:00.000] 8 1",
+	}
+
+	var events []*Event
+	for _, line := range lines {
+		e, err := ParseEvent(line)
+		if err != nil {
+			t.Fatalf("ParseEvent(%q) error = %v", line, err)
+		}
+		events = append(events, e)
+	}
+
+	ResolveDates(events, time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC))
+
+	want := []time.Time{
+		time.Date(2025, 12, 31, 23, 59, 58, 0, time.UTC),
+		time.Date(2025, 12, 31, 23, 59, 59, 500000000, time.UTC),
+		time.Date(2026, 1, 1, 0, 0, 1, 0, time.UTC),
+		time.Date(2026, 1, 2, 0, 0, 2, 0, time.UTC),
+		time.Date(2026, 1, 1, 23, 59, 0, 0, time.UTC),
+	}
+	for i, w := range want {
+		if !events[i].Time.Equal(w) {
+			t.Errorf("Event %d: expected %v, got %v", i, w, events[i].Time)
+		}
+	}
+}
+
+func TestResolveDates_OutOfOrderAcrossMidnight(t *testing.T) {
+	lines := []string{
+		"[00:00:01.000] 1 2",
+		"[23:59:59.000] 1 3",
+		"[00:05:00.000] 10 1",
+	}
+
+	var events []*Event
+	for _, line := range lines {
+		e, err := ParseEvent(line)
+		if err != nil {
+			t.Fatalf("ParseEvent(%q) error = %v", line, err)
+		}
+		events = append(events, e)
+	}
+
+	ResolveDates(events, time.Date(2025, 12, 31, 23, 30, 0, 0, time.UTC))
+
+	want := []time.Time{
+		time.Date(2026, 1, 1, 0, 0, 1, 0, time.UTC),
+		time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC),
+	}
+	for i, w := range want {
+		if !events[i].Time.Equal(w) {
+			t.Errorf("Event %d: expected %v, got %v", i, w, events[i].Time)
+		}
+	}
+
+	_, report := Merge(NEAR_DUPLICATE_WINDOW, events)
+	if len(report.Reordered) != 1 || report.Reordered[0] != events[1] {
+		t.Errorf("Expected the 23:59:59 event to be reported out of order, got %v", report.Reordered)
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	penalty := []Param{
+		{Name: "duration", Kind: PARAM_DURATION},
+		{Name: "reason", Kind: PARAM_TEXT, Optional: true},
+	}
+	target := []Param{{Name: "target", Kind: PARAM_INT}}
+
+	tests := []struct {
+		name    string
+		schema  []Param
+		values  []string
+		wantErr bool
+	}{
+		{"no params", nil, []string{}, false},
+		{"unexpected param", nil, []string{"1"}, true},
+		{"int", target, []string{"3"}, false},
+		{"missing int", target, []string{}, true},
+		{"invalid int", target, []string{"x"}, true},
+		{"too many", target, []string{"1", "2"}, true},
+		{"duration with reason", penalty, []string{"1m", "Unsporting", "behaviour"}, false},
+		{"duration only", penalty, []string{"00:01:00"}, false},
+		{"invalid duration", penalty, []string{"soon"}, true},
+		{"time", []Param{{Name: "start time", Kind: PARAM_TIME}}, []string{"10:00:00.000"}, false},
+		{"invalid time", []Param{{Name: "start time", Kind: PARAM_TIME}}, []string{"10am"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateParams(tt.schema, tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEventString_RoundTrip(t *testing.T) {
+	lines := []string{
+		"[09:05:59.867] 1 1",
+		"[09:15:00.841] 2 1 09:30:00.000",
+		"[09:49:33.123] 5 1 1",
+		"[09:49:34.650] 6 1 1",
+		"[09:59:05.321] 11 1 Lost in the forest",
+		"[10:05:00.000] 12 3 1m0s Unsporting behaviour",
+		"[2025-02-14 23:59:58.500] 32 7 False start",
+	}
+
+	for _, line := range lines {
+		e, err := ParseEvent(line)
+		if err != nil {
+			t.Fatalf("ParseEvent(%q) error = %v", line, err)
+		}
+		if got := e.String(); got != line {
+			t.Errorf("Expected %q, got %q", line, got)
+		}
+
+		text, err := e.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText() error = %v", err)
+		}
+		var decoded Event
+		if err := decoded.UnmarshalText(text); err != nil {
+			t.Fatalf("UnmarshalText(%q) error = %v", text, err)
+		}
+		if decoded.String() != line {
+			t.Errorf("Expected text round trip %q, got %q", line, decoded.String())
+		}
+	}
+}
+
+func TestEventString_FromPayload(t *testing.T) {
+	e := &Event{
+		Time:         time.Date(0, 1, 1, 10, 5, 0, 0, time.UTC),
+		EventID:      EVENT_TIME_PENALTY,
+		CompetitorID: 3,
+		Payload:      TimePenaltyPayload{Duration: 90 * time.Second, Reason: "Skipped lap"},
+	}
+
+	expected := "[10:05:00.000] 12 3 1m30s Skipped lap"
+	if got := e.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestEventJSON_RoundTrip(t *testing.T) {
+	e, err := ParseEvent("[2025-02-14 10:05:00.000] 12 3 2m Unsporting behaviour")
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	e.Source = "jury.txt"
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	expected := `{"time":"2025-02-14T10:05:00Z","dated":true,"eventId":12,"competitorId":3,"params":["2m","Unsporting","behaviour"],"source":"jury.txt"}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	var decoded Event
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.String() != e.String() || decoded.Source != e.Source || !decoded.Time.Equal(e.Time) {
+		t.Errorf("Expected %v from %s, got %v", e, data, &decoded)
+	}
+	if decoded.Payload != (TimePenaltyPayload{Duration: 2 * time.Minute, Reason: "Unsporting behaviour"}) {
+		t.Errorf("Expected decoded payload, got %#v", decoded.Payload)
+	}
+
+	if err := json.Unmarshal([]byte(`{"time":"2025-02-14T10:05:00Z","eventId":6,"competitorId":3,"params":["x"]}`), &decoded); err == nil {
+		t.Error("Expected error for invalid params")
+	}
+}
+
+func TestWriteEvents_RoundTrip(t *testing.T) {
+	events, err := LoadEvents("../testdata/events.txt")
+	if err != nil {
+		t.Fatalf("LoadEvents() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "events")
+	if err := WriteEventsFile(path, events); err != nil {
+		t.Fatalf("WriteEventsFile() error = %v", err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read written events: %v", err)
+	}
+	original, err := os.ReadFile("../testdata/events.txt")
+	if err != nil {
+		t.Fatalf("Failed to read events: %v", err)
+	}
+
+	if strings.TrimSpace(string(written)) != strings.TrimSpace(string(original)) {
+		t.Errorf("Expected written events to match the original:\n%s\ngot:\n%s", original, written)
+	}
+}
+
+func TestBinary_RoundTrip(t *testing.T) {
+	lines := []string{
+		"[09:05:59.867] 1 1",
+		"[09:15:00.841] 2 1 09:30:00.000",
+		"[09:49:33.123] 5 1 1",
+		"[09:49:34.650] 6 1 1",
+		"[09:45:00.000] 12 3 1m0s Unsporting behaviour",
+		"[23:59:58.500] 11 1 Lost in the forest",
+		"[2025-02-15 00:00:01.000] 32 70000 False start",
+	}
+
+	var events []*Event
+	for _, line := range lines {
+		e, err := ParseEvent(line)
+		if err != nil {
+			t.Fatalf("ParseEvent(%q) error = %v", line, err)
+		}
+		events = append(events, e)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBinaryEvents(&buf, events); err != nil {
+		t.Fatalf("WriteBinaryEvents() error = %v", err)
+	}
+
+	text := strings.Join(lines, "\n") + "\n"
+	if buf.Len() >= len(text) {
+		t.Errorf("Expected binary (%d bytes) to be smaller than text (%d bytes)", buf.Len(), len(text))
+	}
+
+	br, err := NewBinaryReader(&buf)
+	if err != nil {
+		t.Fatalf("NewBinaryReader() error = %v", err)
+	}
+	decoded, err := ReadAll(br)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	if len(decoded) != len(lines) {
+		t.Fatalf("Expected %d events, got %d", len(lines), len(decoded))
+	}
+	for i, e := range decoded {
+		if e.String() != lines[i] {
+			t.Errorf("Expected %q, got %q", lines[i], e.String())
+		}
+		if e.Payload != events[i].Payload {
+			t.Errorf("Expected payload %#v, got %#v", events[i].Payload, e.Payload)
+		}
+	}
+}
+
+func TestBinary_Errors(t *testing.T) {
+	if _, err := NewBinaryReader(strings.NewReader("[09:05:59.867] 1 1")); err == nil {
+		t.Error("Expected error for a text file")
+	}
+
+	var buf bytes.Buffer
+	events := []*Event{{EventID: EVENT_HIT, CompetitorID: 1, ExtraParams: []string{"1"}}}
+	if err := WriteBinaryEvents(&buf, events); err != nil {
+		t.Fatalf("WriteBinaryEvents() error = %v", err)
+	}
+
+	truncated := buf.Bytes()[:buf.Len()-1]
+	br, err := NewBinaryReader(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatalf("NewBinaryReader() error = %v", err)
+	}
+	if _, err := br.Next(); err == nil || !strings.HasPrefix(err.Error(), "record 1: truncated record") {
+		t.Errorf("Expected truncated record error, got %v", err)
+	}
+
+	corrupt := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"huge length", BINARY_MAGIC + "\xff\xff\xff\xff\xff\xff\xff\xff\x7f", "record 1: record length"},
+		{"huge param count", BINARY_MAGIC + "\x0d\x00\x00\x01\x01\xff\xff\xff\xff\xff\xff\xff\xff\x7f", "record 1: record too short"},
+	}
+	for _, tt := range corrupt {
+		br, err := NewBinaryReader(strings.NewReader(tt.data))
+		if err != nil {
+			t.Fatalf("%s: NewBinaryReader() error = %v", tt.name, err)
+		}
+		if _, err := br.Next(); err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%s: expected error %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestLoadEvents_Binary(t *testing.T) {
+	events, err := LoadEvents("../testdata/events_midnight.txt")
+	if err != nil {
+		t.Fatalf("LoadEvents() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "events.bin")
+	if err := WriteBinaryEventsFile(path, events); err != nil {
+		t.Fatalf("WriteBinaryEventsFile() error = %v", err)
+	}
+
+	loaded, err := LoadEvents(path)
+	if err != nil {
+		t.Fatalf("LoadEvents() error = %v", err)
+	}
+	if len(loaded) != len(events) {
+		t.Fatalf("Expected %d events, got %d", len(events), len(loaded))
+	}
+	for i := range loaded {
+		if loaded[i].String() != events[i].String() {
+			t.Errorf("Expected %q, got %q", events[i], loaded[i])
+		}
+		if loaded[i].Source != path {
+			t.Errorf("Expected source %q, got %q", path, loaded[i].Source)
+		}
+	}
+}
+
+// benchLines returns n event lines cycling through the common event types.
+func benchLines(n int) string {
+	templates := []string{
+		"[%s] 1 %d",
+		"[%s] 2 %d 10:00:00.000",
+		"[%s] 4 %d",
+		"[%s] 5 %d 1",
+		"[%s] 6 %d 3",
+		"[%s] 7 %d",
+		"[%s] 10 %d",
+	}
+
+	var b strings.Builder
+	start := time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := range n {
+		clock := start.Add(time.Duration(i) * time.Millisecond).Format("15:04:05.000")
+		fmt.Fprintf(&b, templates[i%len(templates)]+"\n", clock, i%1000+1)
+	}
+	return b.String()
+}
+
+func BenchmarkParseEvent(b *testing.B) {
+	lines := strings.Split(strings.TrimSpace(benchLines(1000)), "\n")
+	b.ReportAllocs()
+
+	for i := 0; b.Loop(); i++ {
+		if _, err := ParseEvent(lines[i%len(lines)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkReader(b *testing.B, data []byte, open func(r io.Reader) Reader) {
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		r := open(bytes.NewReader(data))
+		for {
+			_, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkTextReader(b *testing.B) {
+	data := []byte(benchLines(100_000))
+	benchmarkReader(b, data, func(r io.Reader) Reader {
+		return NewTextReader(r, "bench")
+	})
+}
+
+func BenchmarkTextReader_Reuse(b *testing.B) {
+	data := []byte(benchLines(100_000))
+	benchmarkReader(b, data, func(r io.Reader) Reader {
+		tr := NewTextReader(r, "bench")
+		tr.Reuse = true
+		return tr
+	})
+}
+
+func BenchmarkBinaryReader(b *testing.B) {
+	events, err := ReadAll(NewTextReader(strings.NewReader(benchLines(100_000)), "bench"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteBinaryEvents(&buf, events); err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkReader(b, buf.Bytes(), func(r io.Reader) Reader {
+		br, err := NewBinaryReader(r)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return br
+	})
+}
+
+func TestTextReader_Reuse(t *testing.T) {
+	tr := NewTextReader(strings.NewReader(benchLines(20)), "bench")
+	tr.Reuse = true
+
+	var prev *Event
+	count := 0
+	for {
+		e, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if prev != nil && e != prev {
+			t.Fatal("Expected the same event to be reused")
+		}
+		if e.Source != "bench" {
+			t.Errorf("Expected source bench, got %q", e.Source)
+		}
+		prev = e
+		count++
+	}
+
+	if count != 20 {
+		t.Errorf("Expected 20 events, got %d", count)
+	}
+}
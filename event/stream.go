@@ -0,0 +1,114 @@
+package event
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// TextReader parses events from the text format line by line.
+type TextReader struct {
+	scanner *bufio.Scanner
+	source  string
+	line    int
+
+	// Reuse makes Next return the same event on every call, overwritten by
+	// the next call, so reading allocates almost nothing. Only use it when
+	// events are not kept, e.g. with Processor.ProcessFrom.
+	Reuse  bool
+	reused Event
+}
+
+// NewTextReader reads events from r. source tags the events and error
+// messages.
+func NewTextReader(r io.Reader, source string) *TextReader {
+	return &TextReader{scanner: bufio.NewScanner(r), source: source}
+}
+
+func (tr *TextReader) Next() (*Event, error) {
+	for tr.scanner.Scan() {
+		tr.line++
+		line := strings.TrimSpace(tr.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		e := &tr.reused
+		if !tr.Reuse {
+			e = &Event{}
+		}
+
+		if err := ParseEventInto(line, e); err != nil {
+			return nil, fmt.Errorf("%s:%d: failed to parse line '%s': %v", tr.source, tr.line, line, err)
+		}
+		e.Source = tr.source
+		return e, nil
+	}
+
+	if err := tr.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// EventFile streams events from a text or binary events file.
+type EventFile struct {
+	file   *os.File
+	reader Reader
+	source string
+	binary bool
+}
+
+// OpenEvents opens an events file, detecting its format.
+func OpenEvents(path string) (*EventFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(file)
+	f := &EventFile{file: file, source: path}
+
+	if head, _ := r.Peek(len(BINARY_MAGIC)); IsBinary(head) {
+		br, err := NewBinaryReader(r)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		f.reader, f.binary = br, true
+	} else {
+		f.reader = NewTextReader(r, path)
+	}
+	return f, nil
+}
+
+// Next returns the next event tagged with the file as its source.
+func (f *EventFile) Next() (*Event, error) {
+	e, err := f.reader.Next()
+	if err != nil {
+		if f.binary && err != io.EOF {
+			err = fmt.Errorf("%s: %v", f.source, err)
+		}
+		return nil, err
+	}
+	e.Source = f.source
+	return e, nil
+}
+
+// Binary reports whether the file is in the binary format.
+func (f *EventFile) Binary() bool {
+	return f.binary
+}
+
+// SetReuse makes text files return a reused event, see TextReader.Reuse.
+func (f *EventFile) SetReuse(reuse bool) {
+	if tr, ok := f.reader.(*TextReader); ok {
+		tr.Reuse = reuse
+	}
+}
+
+func (f *EventFile) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,253 @@
+package event
+
+import (
+	"biathlon/config"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IDs of the built-in event types.
+const (
+	EVENT_REGISTRATION      = 1
+	EVENT_START_TIME        = 2
+	EVENT_ON_START_LINE     = 3
+	EVENT_STARTED           = 4
+	EVENT_ON_FIRING_RANGE   = 5
+	EVENT_HIT               = 6
+	EVENT_LEFT_FIRING_RANGE = 7
+	EVENT_ENTERED_PENALTY   = 8
+	EVENT_LEFT_PENALTY      = 9
+	EVENT_ENDED_MAIN_LAP    = 10
+	EVENT_CANT_CONTINUE     = 11
+	EVENT_TIME_PENALTY      = 12
+	EVENT_DISQUALIFIED      = 32
+)
+
+// Kinds of event parameters.
+const (
+	PARAM_TIME     = "time"
+	PARAM_INT      = "int"
+	PARAM_DURATION = "duration"
+	// PARAM_TEXT takes all remaining tokens and must be the last parameter
+	PARAM_TEXT = "text"
+)
+
+// Param describes one parameter of an event type.
+type Param struct {
+	Name     string
+	Kind     string
+	Optional bool
+}
+
+// ValidateParams checks values against the parameter schema: the number of
+// values and that each one parses as its kind.
+func ValidateParams(schema []Param, values []string) error {
+	required := 0
+	for _, p := range schema {
+		if !p.Optional {
+			required++
+		}
+	}
+
+	if len(values) < required {
+		return fmt.Errorf("expected %d parameter(s), got %d", required, len(values))
+	}
+
+	for i, p := range schema {
+		if i >= len(values) {
+			break
+		}
+		if p.Kind == PARAM_TEXT {
+			return nil
+		}
+		if err := p.validate(values[i]); err != nil {
+			return err
+		}
+	}
+
+	if len(values) > len(schema) {
+		return fmt.Errorf("expected at most %d parameter(s), got %d", len(schema), len(values))
+	}
+	return nil
+}
+
+func (p Param) validate(value string) error {
+	var err error
+	switch p.Kind {
+	case PARAM_TIME:
+		_, _, err = ParseTime(value)
+	case PARAM_INT:
+		_, err = strconv.Atoi(value)
+	case PARAM_DURATION:
+		_, err = config.ParseDuration(value)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: expected %s", p.Name, value, p.Kind)
+	}
+	return nil
+}
+
+// Type describes a built-in event type and how its parameters decode into
+// a typed payload.
+type Type struct {
+	ID     int
+	Name   string
+	Params []Param
+	decode func(params []string) Payload
+}
+
+// Payload holds the typed parameters of a built-in event.
+type Payload interface {
+	// Params renders the payload back into event line parameters.
+	Params() []string
+}
+
+type StartTimePayload struct {
+	Time time.Time
+	// Dated is set when the start time carried a full date
+	Dated bool
+}
+
+type FiringRangePayload struct {
+	Range int
+}
+
+type TargetPayload struct {
+	Target int
+}
+
+// ReasonPayload is the free text of can't continue and disqualified events.
+type ReasonPayload struct {
+	Text string
+}
+
+type TimePenaltyPayload struct {
+	Duration time.Duration
+	Reason   string
+}
+
+func (p StartTimePayload) Params() []string {
+	if p.Dated {
+		return []string{p.Time.Format("2006-01-02T15:04:05.000")}
+	}
+	return []string{p.Time.Format(config.TIME_FORMAT_WITH_MS)}
+}
+
+func (p FiringRangePayload) Params() []string {
+	return []string{strconv.Itoa(p.Range)}
+}
+
+func (p TargetPayload) Params() []string {
+	return []string{strconv.Itoa(p.Target)}
+}
+
+func (p ReasonPayload) Params() []string {
+	return strings.Fields(p.Text)
+}
+
+func (p TimePenaltyPayload) Params() []string {
+	return append([]string{p.Duration.String()}, strings.Fields(p.Reason)...)
+}
+
+var builtinTypes = map[int]Type{
+	EVENT_REGISTRATION:  {Name: "Registration"},
+	EVENT_ON_START_LINE: {Name: "On the start line"},
+	EVENT_STARTED:       {Name: "Started"},
+	EVENT_START_TIME: {
+		Name:   "Start time",
+		Params: []Param{{Name: "start time", Kind: PARAM_TIME}},
+		decode: func(params []string) Payload {
+			t, dated, _ := ParseTime(params[0])
+			return StartTimePayload{Time: t, Dated: dated}
+		},
+	},
+	EVENT_ON_FIRING_RANGE: {
+		Name:   "On the firing range",
+		Params: []Param{{Name: "firing range", Kind: PARAM_INT}},
+		decode: func(params []string) Payload {
+			n, _ := strconv.Atoi(params[0])
+			return FiringRangePayload{Range: n}
+		},
+	},
+	EVENT_HIT: {
+		Name:   "Hit",
+		Params: []Param{{Name: "target", Kind: PARAM_INT}},
+		decode: func(params []string) Payload {
+			n, _ := strconv.Atoi(params[0])
+			return TargetPayload{Target: n}
+		},
+	},
+	EVENT_LEFT_FIRING_RANGE: {Name: "Left the firing range"},
+	EVENT_ENTERED_PENALTY:   {Name: "Entered the penalty laps"},
+	EVENT_LEFT_PENALTY:      {Name: "Left the penalty laps"},
+	EVENT_ENDED_MAIN_LAP:    {Name: "Ended the main lap"},
+	EVENT_CANT_CONTINUE: {
+		Name:   "Can't continue",
+		Params: []Param{{Name: "reason", Kind: PARAM_TEXT, Optional: true}},
+		decode: decodeReason,
+	},
+	EVENT_TIME_PENALTY: {
+		Name: "Time penalty",
+		Params: []Param{
+			{Name: "duration", Kind: PARAM_DURATION},
+			{Name: "reason", Kind: PARAM_TEXT, Optional: true},
+		},
+		decode: func(params []string) Payload {
+			d, _ := config.ParseDuration(params[0])
+			return TimePenaltyPayload{Duration: d, Reason: strings.Join(params[1:], " ")}
+		},
+	},
+	EVENT_DISQUALIFIED: {
+		Name:   "Disqualified",
+		Params: []Param{{Name: "reason", Kind: PARAM_TEXT, Optional: true}},
+		decode: decodeReason,
+	},
+}
+
+func decodeReason(params []string) Payload {
+	return ReasonPayload{Text: strings.Join(params, " ")}
+}
+
+// BuiltinType returns the built-in event type with the given ID.
+func BuiltinType(id int) (Type, bool) {
+	t, ok := builtinTypes[id]
+	t.ID = id
+	return t, ok
+}
+
+// ParsePayload validates the parameters of a built-in event type and
+// decodes them into its payload. Event types without parameters and
+// unknown IDs have a nil payload.
+func ParsePayload(id int, params []string) (Payload, error) {
+	t, ok := BuiltinType(id)
+	if !ok {
+		return nil, nil
+	}
+
+	if err := ValidateParams(t.Params, params); err != nil {
+		return nil, fmt.Errorf("event %d (%s): %v", id, t.Name, err)
+	}
+
+	if t.decode == nil {
+		return nil, nil
+	}
+	return t.decode(params), nil
+}
+
+// DecodePayload fills in the payload of a built-in event created without
+// ParseEvent, e.g. in code.
+func (e *Event) DecodePayload() error {
+	if e.Payload != nil {
+		return nil
+	}
+
+	payload, err := ParsePayload(e.EventID, e.ExtraParams)
+	if err != nil {
+		return err
+	}
+	e.Payload = payload
+	return nil
+}
@@ -0,0 +1,71 @@
+// Package i18n translates log messages, status labels and report headers.
+// Messages are keyed by their English text, so English needs no catalog
+// and untranslated messages fall back to English.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	LANG_EN = "en"
+	LANG_RU = "ru"
+	LANG_NO = "no"
+)
+
+var catalogs = map[string]map[string]string{
+	LANG_EN: {},
+	LANG_RU: ru,
+	LANG_NO: no,
+}
+
+// Catalog translates messages into a single language. A nil Catalog
+// leaves messages in English.
+type Catalog struct {
+	Lang     string
+	messages map[string]string
+}
+
+// English is the default catalog.
+var English = &Catalog{Lang: LANG_EN, messages: catalogs[LANG_EN]}
+
+// Lookup returns the catalog for a language code.
+func Lookup(lang string) (*Catalog, error) {
+	if lang == "" {
+		return English, nil
+	}
+
+	messages, ok := catalogs[lang]
+	if !ok {
+		return nil, fmt.Errorf("unknown language %q, available: %s", lang, strings.Join(Languages(), ", "))
+	}
+	return &Catalog{Lang: lang, messages: messages}, nil
+}
+
+// Languages lists the supported language codes.
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// T translates a message.
+func (c *Catalog) T(msg string) string {
+	if c == nil {
+		return msg
+	}
+	if translated, ok := c.messages[msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf translates a format string and formats it with args.
+func (c *Catalog) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(c.T(format), args...)
+}
@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+)
+
+var verbRe = regexp.MustCompile(`%[a-z]`)
+
+func TestCatalogsComplete(t *testing.T) {
+	for lang, messages := range catalogs {
+		if lang == LANG_EN {
+			continue
+		}
+
+		for other, otherMessages := range catalogs {
+			if other == LANG_EN || other == lang {
+				continue
+			}
+			for key := range otherMessages {
+				if _, ok := messages[key]; !ok {
+					t.Errorf("Expected %s catalog to translate %q", lang, key)
+				}
+			}
+		}
+
+		for key, msg := range messages {
+			want := verbRe.FindAllString(key, -1)
+			got := verbRe.FindAllString(msg, -1)
+			if len(want) != len(got) {
+				t.Errorf("%s: expected verbs %v in %q, got %v", lang, want, msg, got)
+				continue
+			}
+			for i := range want {
+				if want[i] != got[i] {
+					t.Errorf("%s: expected verbs %v in %q, got %v", lang, want, msg, got)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	ru, err := Lookup(LANG_RU)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	if got := ru.Sprintf("The competitor(%d) registered", 5); got != "Участник(5) зарегистрирован" {
+		t.Errorf("Expected translated message, got %q", got)
+	}
+
+	if got := ru.T("Not in catalog"); got != "Not in catalog" {
+		t.Errorf("Expected untranslated message to fall back to English, got %q", got)
+	}
+
+	en, err := Lookup("")
+	if err != nil || en != English {
+		t.Errorf("Expected English by default, got %v, %v", en, err)
+	}
+
+	var nilCat *Catalog
+	if got := nilCat.Sprintf("Lap %d", 1); got != "Lap 1" {
+		t.Errorf("Expected nil catalog to format in English, got %q", got)
+	}
+
+	if _, err := Lookup("de"); err == nil {
+		t.Error("Expected error for unknown language")
+	}
+}
@@ -0,0 +1,80 @@
+package i18n
+
+var no = map[string]string{
+	// Output log
+	"The competitor(%d) registered":                                                 "Løper(%d) er registrert",
+	"The start time of competitor(%d) was set by a draw to %s":                      "Starttiden til løper(%d) ble trukket til %s",
+	"The competitor(%d) is on the start line":                                       "Løper(%d) er på startstreken",
+	"The competitor(%d) is disqualified":                                            "Løper(%d) er diskvalifisert",
+	"The competitor(%d) has started":                                                "Løper(%d) har startet",
+	"The competitor(%d) is on the firing range(%d)":                                 "Løper(%d) er på standplass(%d)",
+	"The target(%d) has been hit by competitor(%d)":                                 "Blink(%d) er truffet av løper(%d)",
+	"The competitor(%d) left the firing range":                                      "Løper(%d) forlot standplassen",
+	"%d missed shot(s)":                                                             "%d bom",
+	"The competitor(%d) entered the penalty laps":                                   "Løper(%d) gikk inn i strafferunden",
+	"The competitor(%d) left the penalty laps":                                      "Løper(%d) forlot strafferunden",
+	"The competitor(%d) ended the main lap":                                         "Løper(%d) fullførte runden",
+	"The competitor can`t continue: %s":                                             "Løperen kan ikke fortsette: %s",
+	"The competitor(%d) received a time penalty of %s (%s)":                         "Løper(%d) fikk et tidstillegg på %s (%s)",
+	"Event %d of competitor(%d) from %s arrived out of order":                       "Hendelse %d for løper(%d) fra %s kom i feil rekkefølge",
+	"Dropped %s duplicate of event %d of competitor(%d) from %s (original from %s)": "Forkastet %s duplikat av hendelse %d for løper(%d) fra %s (original fra %s)",
+	"Unknown event %d of competitor(%d) ignored":                                    "Ukjent hendelse %d for løper(%d) ignorert",
+	"Event %d (%s) of competitor(%d) ignored: %v":                                   "Hendelse %d (%s) for løper(%d) ignorert: %v",
+	"exact":                       "eksakt",
+	"near":                        "nesten likt",
+	"Correction(line %d): %s: %s": "Korreksjon(linje %d): %s: %s",
+	"event added":                 "hendelse lagt til",
+	"event removed":               "hendelse fjernet",
+	"event amended":               "hendelse endret",
+	"time penalty added":          "tidstillegg lagt til",
+	"competitor disqualified":     "løper diskvalifisert",
+	"jury":                        "jury",
+	"missed shot":                 "bom",
+
+	// Status labels
+	"Finished":     "Fullført",
+	"NotStarted":   "Ikke startet",
+	"NotFinished":  "Ikke fullført",
+	"Disqualified": "Diskvalifisert",
+
+	// Results and report headers
+	"raw %s + penalty %s":      "løpstid %s + tillegg %s",
+	"Race results":             "Resultater",
+	"Official results":         "Offisielle resultater",
+	"Not classified":           "Ikke klassifisert",
+	"Competitors":              "Løpere",
+	"Rank":                     "Plass",
+	"rank":                     "plass",
+	"Bib":                      "Startnr.",
+	"bib":                      "startnr.",
+	"Status":                   "Status",
+	"Time":                     "Tid",
+	"Raw time":                 "Løpstid",
+	"Time penalty":             "Tidstillegg",
+	"penalties":                "tillegg",
+	"Behind":                   "Etter",
+	"Laps":                     "Runder",
+	"Lap":                      "Runde",
+	"Penalty laps":             "Strafferunder",
+	"Penalty lap":              "Strafferunde",
+	"penalty lap":              "strafferunde",
+	"Shooting":                 "Skyting",
+	"Hits":                     "Treff",
+	"Misses":                   "Bom",
+	"Firing line":              "Skyting",
+	"Firing lines":             "Skytinger",
+	"firing line(s)":           "skyting(er)",
+	"Reason":                   "Årsak",
+	"Source":                   "Kilde",
+	"Date":                     "Dato",
+	"Planned start":            "Planlagt start",
+	"Actual start":             "Faktisk start",
+	"Finish":                   "Mål",
+	"Speed, m/s":               "Fart, m/s",
+	"Jury":                     "Jury",
+	"Signature":                "Signatur",
+	"Page":                     "Side",
+	"Includes time penalties:": "Inkluderer tidstillegg:",
+	"Times include time penalties. Shooting shows hits per firing line.": "Tidene inkluderer tidstillegg. Skyting viser treff per skyting.",
+	"DNS: did not start. DNF: did not finish. DSQ: disqualified.":        "DNS: ikke startet. DNF: ikke fullført. DSQ: diskvalifisert.",
+}
@@ -0,0 +1,80 @@
+package i18n
+
+var ru = map[string]string{
+	// Output log
+	"The competitor(%d) registered":                                                 "Участник(%d) зарегистрирован",
+	"The start time of competitor(%d) was set by a draw to %s":                      "Время старта участника(%d) определено жеребьёвкой: %s",
+	"The competitor(%d) is on the start line":                                       "Участник(%d) на линии старта",
+	"The competitor(%d) is disqualified":                                            "Участник(%d) дисквалифицирован",
+	"The competitor(%d) has started":                                                "Участник(%d) стартовал",
+	"The competitor(%d) is on the firing range(%d)":                                 "Участник(%d) на огневом рубеже(%d)",
+	"The target(%d) has been hit by competitor(%d)":                                 "Мишень(%d) поражена участником(%d)",
+	"The competitor(%d) left the firing range":                                      "Участник(%d) покинул огневой рубеж",
+	"%d missed shot(s)":                                                             "промахов: %d",
+	"The competitor(%d) entered the penalty laps":                                   "Участник(%d) вышел на штрафной круг",
+	"The competitor(%d) left the penalty laps":                                      "Участник(%d) покинул штрафной круг",
+	"The competitor(%d) ended the main lap":                                         "Участник(%d) завершил основной круг",
+	"The competitor can`t continue: %s":                                             "Участник не может продолжить: %s",
+	"The competitor(%d) received a time penalty of %s (%s)":                         "Участник(%d) получил штраф времени %s (%s)",
+	"Event %d of competitor(%d) from %s arrived out of order":                       "Событие %d участника(%d) из %s пришло не по порядку",
+	"Dropped %s duplicate of event %d of competitor(%d) from %s (original from %s)": "Отброшен %s дубликат события %d участника(%d) из %s (оригинал из %s)",
+	"Unknown event %d of competitor(%d) ignored":                                    "Неизвестное событие %d участника(%d) пропущено",
+	"Event %d (%s) of competitor(%d) ignored: %v":                                   "Событие %d (%s) участника(%d) пропущено: %v",
+	"exact":                       "точный",
+	"near":                        "близкий",
+	"Correction(line %d): %s: %s": "Поправка(строка %d): %s: %s",
+	"event added":                 "событие добавлено",
+	"event removed":               "событие удалено",
+	"event amended":               "событие изменено",
+	"time penalty added":          "добавлен штраф времени",
+	"competitor disqualified":     "участник дисквалифицирован",
+	"jury":                        "жюри",
+	"missed shot":                 "промах",
+
+	// Status labels
+	"Finished":     "Финишировал",
+	"NotStarted":   "Не стартовал",
+	"NotFinished":  "Не финишировал",
+	"Disqualified": "Дисквалифицирован",
+
+	// Results and report headers
+	"raw %s + penalty %s":      "чистое %s + штраф %s",
+	"Race results":             "Результаты гонки",
+	"Official results":         "Официальные результаты",
+	"Not classified":           "Не классифицированы",
+	"Competitors":              "Участники",
+	"Rank":                     "Место",
+	"rank":                     "место",
+	"Bib":                      "Номер",
+	"bib":                      "номер",
+	"Status":                   "Статус",
+	"Time":                     "Время",
+	"Raw time":                 "Чистое время",
+	"Time penalty":             "Штраф времени",
+	"penalties":                "штрафы",
+	"Behind":                   "Отставание",
+	"Laps":                     "Круги",
+	"Lap":                      "Круг",
+	"Penalty laps":             "Штрафные круги",
+	"Penalty lap":              "Штрафной круг",
+	"penalty lap":              "штрафной круг",
+	"Shooting":                 "Стрельба",
+	"Hits":                     "Попадания",
+	"Misses":                   "Промахи",
+	"Firing line":              "Огневой рубеж",
+	"Firing lines":             "Огневые рубежи",
+	"firing line(s)":           "огневых рубежа(ей)",
+	"Reason":                   "Причина",
+	"Source":                   "Источник",
+	"Date":                     "Дата",
+	"Planned start":            "Плановый старт",
+	"Actual start":             "Фактический старт",
+	"Finish":                   "Финиш",
+	"Speed, m/s":               "Скорость, м/с",
+	"Jury":                     "Жюри",
+	"Signature":                "Подпись",
+	"Page":                     "Страница",
+	"Includes time penalties:": "Включает штрафы времени:",
+	"Times include time penalties. Shooting shows hits per firing line.": "Время включает штрафы времени. В графе «Стрельба» указаны попадания на каждом огневом рубеже.",
+	"DNS: did not start. DNF: did not finish. DSQ: disqualified.":        "DNS: не стартовал. DNF: не финишировал. DSQ: дисквалифицирован.",
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"biathlon/config"
+	"biathlon/processor"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+type jsonReport struct {
+	Date     string             `json:"date,omitempty"`
+	Timezone string             `json:"timezone"`
+	Start    time.Time          `json:"start"`
+	Results  []processor.Result `json:"results"`
+}
+
+func writeJSONResults(path string, proc *processor.Processor) error {
+	cfg := proc.Config
+
+	report := jsonReport{
+		Timezone: proc.Location.String(),
+		Start:    cfg.Start.In(proc.Location),
+		Results:  proc.Results(),
+	}
+	if cfg.Date.Year() > 0 {
+		report.Date = cfg.Date.Format(config.DATE_FORMAT)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
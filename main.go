@@ -2,52 +2,331 @@ package main
 
 import (
 	"biathlon/config"
+	"biathlon/corrections"
 	"biathlon/event"
+	"biathlon/i18n"
 	"biathlon/processor"
+	"biathlon/report"
 	"bufio"
+	"bytes"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
+	"time"
 )
 
-func runApp(cfgPath, evsPath string) ([]string, []string, error) {
-	cfg, err := config.LoadConfig(cfgPath)
+type options struct {
+	cfgPath         string
+	evsPath         string // comma-separated event files or glob patterns
+	correctionsPath string
+	jsonPath        string
+	htmlPath        string
+	typstPath       string
+	table           string // text or markdown, empty for the plain results
+	columns         string // comma-separated table columns
+	logFormat       string // text (default) or jsonl
+	lang            string // output language, English when empty
+	utc             bool
+	sets            []string // key=value config overrides
+}
+
+const usage = "Usage: [-corrections path] [-json path] [-html path] [-typst path] [-table text|markdown] [-columns list] [-log-format text|jsonl] [-lang en|ru|no] [-utc] [-set key=value]... <config_path> <events_paths> [output_logs_path] [results_path]"
+
+// stringList is a flag that may be given several times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func runApp(opts options) ([]string, []string, error) {
+	lang, err := i18n.Lookup(opts.lang)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawCfg, err := loadRawConfig(opts.cfgPath, opts.sets)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading config: %v", err)
+	}
+
+	cfg, err := rawCfg.Build()
 	if err != nil {
 		return nil, nil, fmt.Errorf("error loading config: %v", err)
 	}
 
-	evs, err := event.LoadEvents(evsPath)
+	sources, err := event.LoadEventSources(strings.Split(opts.evsPath, ",")...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error loading events: %v", err)
 	}
 
+	for _, src := range sources {
+		event.ResolveDates(src, cfg.Start)
+	}
+
+	evs, merged := event.Merge(event.NEAR_DUPLICATE_WINDOW, sources...)
+	notes := mergeNotes(merged, lang)
+
+	if opts.correctionsPath != "" {
+		corrs, err := corrections.LoadCorrections(opts.correctionsPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error loading corrections: %v", err)
+		}
+
+		var corrNotes []note
+		evs, corrNotes, err = applyCorrections(evs, corrs, cfg, lang)
+		if err != nil {
+			return nil, nil, err
+		}
+		notes = append(notes, corrNotes...)
+	}
+
+	proc := newProcessor(cfg, evs, opts)
+	proc.Lang = lang
+	proc.ShowSource = len(sources) > 1
+	logs, results := processRace(proc, notes...)
+
+	if opts.logFormat != "" && opts.logFormat != processor.LOG_FORMAT_TEXT {
+		logs, err = renderLogs(opts.logFormat, proc.Logs)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if opts.jsonPath != "" {
+		if err := writeJSONResults(opts.jsonPath, proc); err != nil {
+			return nil, nil, fmt.Errorf("error writing json results: %v", err)
+		}
+	}
+
+	if opts.table != "" {
+		results, err = renderTable(opts, proc.Results(), lang)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	page := report.Page{Title: lang.T("Race results"), Config: cfg, Results: proc.Results(), Lang: lang}
+
+	if opts.htmlPath != "" {
+		if err := report.WriteHTMLFile(opts.htmlPath, page); err != nil {
+			return nil, nil, fmt.Errorf("error writing html results: %v", err)
+		}
+	}
+
+	if opts.typstPath != "" {
+		if err := report.WriteTypstFile(opts.typstPath, page); err != nil {
+			return nil, nil, fmt.Errorf("error writing results sheet: %v", err)
+		}
+	}
+	return logs, results, nil
+}
+
+// renderLogs renders the log in a machine-readable format, one line per
+// entry.
+func renderLogs(format string, entries []processor.LogEntry) ([]string, error) {
+	if format != processor.LOG_FORMAT_JSONL {
+		return nil, fmt.Errorf("unknown log format %q, expected %s or %s", format, processor.LOG_FORMAT_TEXT, processor.LOG_FORMAT_JSONL)
+	}
+
+	var buf bytes.Buffer
+	if err := processor.WriteJSONLines(&buf, entries); err != nil {
+		return nil, fmt.Errorf("error rendering logs: %v", err)
+	}
+
+	out := strings.TrimSuffix(buf.String(), "\n")
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// renderTable replaces the plain results rows with an aligned table.
+func renderTable(opts options, results []processor.Result, lang *i18n.Catalog) ([]string, error) {
+	var names []string
+	if opts.columns != "" {
+		names = strings.Split(opts.columns, ",")
+	}
+
+	cols, err := report.SelectColumns(names)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting columns: %v", err)
+	}
+
+	lines, err := report.RenderTable(opts.table, results, cols, lang)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering table: %v", err)
+	}
+	return lines, nil
+}
+
+// loadRawConfig reads the config file and layers BIATHLON_* environment
+// variables and then --set overrides on top of it.
+func loadRawConfig(path string, sets []string) (*config.ConfigRaw, error) {
+	rawCfg, err := config.LoadRaw(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rawCfg.ApplyEnv(os.Environ()); err != nil {
+		return nil, err
+	}
+
+	if err := rawCfg.ApplySets(sets); err != nil {
+		return nil, err
+	}
+	return rawCfg, nil
+}
+
+func newProcessor(cfg *config.Config, evs []*event.Event, opts options) *processor.Processor {
 	proc := processor.NewProcessor(cfg, evs)
+	if opts.utc {
+		proc.Location = time.UTC
+	}
+	return proc
+}
+
+// note is a message about the input (merge report, corrections audit)
+// logged before the race events.
+type note struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+}
+
+// applyCorrections applies corrections to events, returning the audit trail
+// as notes.
+func applyCorrections(evs []*event.Event, corrs []*corrections.Correction, cfg *config.Config, lang *i18n.Catalog) ([]*event.Event, []note, error) {
+	corrections.ResolveDates(corrs, cfg.Start)
+
+	evs, audit, err := corrections.Apply(evs, corrs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error applying corrections: %v", err)
+	}
+
+	var notes []note
+	for _, a := range audit {
+		notes = append(notes, note{Time: a.Time, Level: slog.LevelInfo, Message: lang.Sprintf("Correction(line %d): %s: %s", a.Line, lang.T(a.What), a.Text)})
+	}
+	return evs, notes, nil
+}
+
+func mergeNotes(report *event.MergeReport, lang *i18n.Catalog) []note {
+	var notes []note
+
+	for _, e := range report.Reordered {
+		notes = append(notes, note{
+			Time:    e.Time,
+			Level:   slog.LevelWarn,
+			Message: lang.Sprintf("Event %d of competitor(%d) from %s arrived out of order", e.EventID, e.CompetitorID, e.Source),
+		})
+	}
+
+	for _, d := range report.Duplicates {
+		kind := "exact"
+		if !d.Exact {
+			kind = "near"
+		}
+		notes = append(notes, note{
+			Time:  d.Event.Time,
+			Level: slog.LevelWarn,
+			Message: lang.Sprintf(
+				"Dropped %s duplicate of event %d of competitor(%d) from %s (original from %s)",
+				lang.T(kind), d.Event.EventID, d.Event.CompetitorID, d.Event.Source, d.Original.Source,
+			),
+		})
+	}
+
+	return notes
+}
+
+func processRace(proc *processor.Processor, notes ...note) ([]string, []string) {
+	for _, n := range notes {
+		proc.Log(processor.LogEntry{Time: n.Time, Level: n.Level, Message: n.Message})
+	}
 	proc.ProcessEvents()
 
-	var logs []string
-	logs = append(logs, proc.Logs...)
+	logs := proc.TextLogs()
 
 	var results []string
 	results = append(results, proc.GenerateResults()...)
-	return logs, results, nil
+	return logs, results
+}
+
+var commands = map[string]func(args []string) error{
+	"batch":           runBatchCommand,
+	"convert":         runConvert,
+	"db":              runDB,
+	"print-config":    runPrintConfig,
+	"replay":          runReplay,
+	"validate-config": runValidateConfig,
 }
 
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: <config_path> <events_path>")
-		os.Exit(1)
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				fmt.Printf("error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
 	}
 
-	if len(os.Args) == 4 {
-		fmt.Println("Usage: <config_path> <events_path> [output_logs_path] [results_path]")
+	correctionsPath := flag.String("corrections", "", "path to a corrections file applied on top of the events")
+	jsonPath := flag.String("json", "", "write results as JSON to this file")
+	htmlPath := flag.String("html", "", "write results as an HTML page to this file")
+	typstPath := flag.String("typst", "", "write a printable results sheet as typst source to this file")
+	table := flag.String("table", "", "render results as an aligned table: text or markdown")
+	columns := flag.String("columns", "", "comma-separated table columns: "+strings.Join(report.ColumnNames(), ","))
+	logFormat := flag.String("log-format", processor.LOG_FORMAT_TEXT, "output log format: text or jsonl")
+	lang := flag.String("lang", i18n.LANG_EN, "output language: "+strings.Join(i18n.Languages(), ", "))
+	utc := flag.Bool("utc", false, "render times in UTC instead of the race time zone")
+	var sets stringList
+	flag.Var(&sets, "set", "override a config value, key=value (repeatable)")
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) < 2 || len(args) == 3 {
+		fmt.Println(usage)
 		os.Exit(1)
 	}
 
-	logs, results, err := runApp(os.Args[1], os.Args[2])
+	logs, results, err := runApp(options{
+		cfgPath:         args[0],
+		evsPath:         args[1],
+		correctionsPath: *correctionsPath,
+		jsonPath:        *jsonPath,
+		htmlPath:        *htmlPath,
+		typstPath:       *typstPath,
+		table:           *table,
+		columns:         *columns,
+		logFormat:       *logFormat,
+		lang:            *lang,
+		utc:             *utc,
+		sets:            sets,
+	})
 	if err != nil {
 		fmt.Printf("error: %v\n", err)
 		os.Exit(1)
 	}
 
+	printOutput(logs, results)
+
+	// Write output log & resulting table to files
+	if len(args) == 4 {
+		writeInFiles(args[2], args[3], logs, results)
+	}
+}
+
+func printOutput(logs, results []string) {
 	fmt.Println("===Output log===")
 	for _, log := range logs {
 		fmt.Println(log)
@@ -57,11 +336,6 @@ func main() {
 	for _, row := range results {
 		fmt.Println(row)
 	}
-
-	// Write output log & resulting table to files
-	if len(os.Args) == 5 {
-		writeInFiles(os.Args[3], os.Args[4], logs, results)
-	}
 }
 
 func writeInFiles(logPath, resPath string, logs, results []string) {
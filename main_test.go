@@ -1,38 +1,115 @@
 package main
 
 import (
+	"biathlon/storage"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestRunApp(t *testing.T) {
 	tests := []struct {
 		name        string
-		cfgPath     string
-		evsPath     string
+		opts        options
 		wantLogs    []string
 		wantResults []string
 		wantErr     bool
 	}{
 		{
 			name:        "valid input",
-			cfgPath:     "testdata/config.json",
-			evsPath:     "testdata/events.txt",
+			opts:        options{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt"},
 			wantLogs:    []string{"[10:00:00.000] The competitor(1) registered"},
 			wantResults: []string{"[NotStarted] 1 [{,}, {,}] {,} 0/10"},
 			wantErr:     false,
 		},
+		{
+			name: "with corrections",
+			opts: options{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt", correctionsPath: "testdata/corrections.txt"},
+			wantLogs: []string{
+				"[10:05:00.000] Correction(line 2): competitor disqualified: disqualify [10:05:00.000] 1 False start",
+				"[10:00:00.000] The competitor(1) registered",
+				"[10:05:00.000] The competitor(1) is disqualified: False start",
+			},
+			wantResults: []string{"[Disqualified] 1 [{,}, {,}] {,} 0/10"},
+			wantErr:     false,
+		},
+		{
+			name:     "markdown table",
+			opts:     options{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt", table: "markdown", columns: "bib,status"},
+			wantLogs: []string{"[10:00:00.000] The competitor(1) registered"},
+			wantResults: []string{
+				"| Bib | Status     |",
+				"| --: | :--------- |",
+				"|   1 | NotStarted |",
+			},
+			wantErr: false,
+		},
+		{
+			name:        "unknown table column",
+			opts:        options{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt", table: "text", columns: "bib,nope"},
+			wantLogs:    nil,
+			wantResults: nil,
+			wantErr:     true,
+		},
+		{
+			name:        "json lines log",
+			opts:        options{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt", logFormat: "jsonl"},
+			wantLogs:    []string{`{"time":"0000-01-01T10:00:00Z","level":"INFO","msg":"The competitor(1) registered","eventId":1,"competitorId":1,"source":"testdata/events.txt"}`},
+			wantResults: []string{"[NotStarted] 1 [{,}, {,}] {,} 0/10"},
+			wantErr:     false,
+		},
+		{
+			name: "several sources",
+			opts: options{cfgPath: "testdata/config.json", evsPath: "testdata/stations/*.txt"},
+			wantLogs: []string{
+				"[10:00:00.000] The competitor(1) registered [testdata/stations/start.txt]",
+				"[10:00:30.000] The start time of competitor(1) was set by a draw to 10:01:00.000 [testdata/stations/start.txt]",
+				"[10:01:05.000] The competitor(1) has started [testdata/stations/finish.txt]",
+			},
+			wantResults: []string{"[NotFinished] 1 [{,}, {,}] {,} 0/10"},
+			wantErr:     false,
+		},
+		{
+			name:        "unknown log format",
+			opts:        options{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt", logFormat: "xml"},
+			wantLogs:    nil,
+			wantResults: nil,
+			wantErr:     true,
+		},
+		{
+			name:        "russian output",
+			opts:        options{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt", lang: "ru"},
+			wantLogs:    []string{"[10:00:00.000] Участник(1) зарегистрирован"},
+			wantResults: []string{"[Не стартовал] 1 [{,}, {,}] {,} 0/10"},
+			wantErr:     false,
+		},
+		{
+			name:        "unknown language",
+			opts:        options{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt", lang: "xx"},
+			wantLogs:    nil,
+			wantResults: nil,
+			wantErr:     true,
+		},
+		{
+			name:        "invalid corrections path",
+			opts:        options{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt", correctionsPath: "testdata/invalid_corrections.txt"},
+			wantLogs:    nil,
+			wantResults: nil,
+			wantErr:     true,
+		},
 		{
 			name:        "invalid config path",
-			cfgPath:     "testdata/invalid_config.json",
-			evsPath:     "testdata/events.txt",
+			opts:        options{cfgPath: "testdata/invalid_config.json", evsPath: "testdata/events.txt"},
 			wantLogs:    nil,
 			wantResults: nil,
 			wantErr:     true,
 		},
 		{
 			name:        "invalid events path",
-			cfgPath:     "testdata/config.json",
-			evsPath:     "testdata/invalid_events.txt",
+			opts:        options{cfgPath: "testdata/config.json", evsPath: "testdata/invalid_events.txt"},
 			wantLogs:    nil,
 			wantResults: nil,
 			wantErr:     true,
@@ -41,7 +118,7 @@ func TestRunApp(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotLogs, gotResults, err := runApp(tt.cfgPath, tt.evsPath)
+			gotLogs, gotResults, err := runApp(tt.opts)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("runApp() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -67,3 +144,135 @@ func equal(a, b []string) bool {
 	}
 	return true
 }
+
+func TestRunApp_JSONAndUTC(t *testing.T) {
+	jsonPath := filepath.Join(t.TempDir(), "results.json")
+
+	logs, _, err := runApp(options{
+		cfgPath:  "testdata/config_tz.json",
+		evsPath:  "testdata/events_midnight.txt",
+		jsonPath: jsonPath,
+		utc:      true,
+	})
+	if err != nil {
+		t.Fatalf("runApp() error = %v", err)
+	}
+
+	if logs[0] != "[22:50:00.000] The competitor(1) registered" {
+		t.Errorf("Expected log in UTC, got %q", logs[0])
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read json results: %v", err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to decode json results: %v", err)
+	}
+
+	if report.Date != "2025-12-31" {
+		t.Errorf("Expected date 2025-12-31, got %s", report.Date)
+	}
+	if len(report.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(report.Results))
+	}
+
+	finish := report.Results[0].FinishTime
+	expected := time.Date(2025, 12, 31, 23, 10, 0, 0, time.UTC)
+	if finish == nil || !finish.Equal(expected) {
+		t.Errorf("Expected finish time %v, got %v", expected, finish)
+	}
+	if !strings.Contains(string(data), `"finishTime": "2025-12-31T23:10:00Z"`) {
+		t.Error("Expected ISO-8601 finish time in json output")
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	races := []batchRace{
+		{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt"},
+		{cfgPath: "testdata/invalid_config.json", evsPath: "testdata/events.txt"},
+		{cfgPath: "testdata/config_tz.json", evsPath: "testdata/events_midnight.txt"},
+		{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt"},
+	}
+
+	got := runBatch(races, 3, options{})
+	if len(got) != len(races) {
+		t.Fatalf("Expected %d results, got %d", len(races), len(got))
+	}
+
+	for i, res := range got {
+		if res.race != races[i] {
+			t.Errorf("Expected race %d to be %v, got %v", i, races[i], res.race)
+		}
+
+		if i == 1 {
+			if res.err == nil {
+				t.Error("Expected error for the invalid config")
+			}
+			continue
+		}
+		if res.err != nil {
+			t.Errorf("Race %d: unexpected error %v", i, res.err)
+			continue
+		}
+
+		logs, results, _ := runApp(options{cfgPath: races[i].cfgPath, evsPath: races[i].evsPath})
+		if !equal(res.logs, logs) || !equal(res.results, results) {
+			t.Errorf("Race %d: expected the same output as a single run, got %v %v", i, res.logs, res.results)
+		}
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "season.txt")
+	content := "# season\ntestdata/config.json testdata/events.txt\n\ntestdata/config_tz.json testdata/events_midnight.txt\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	races, err := loadManifest(path)
+	if err != nil {
+		t.Fatalf("loadManifest() error = %v", err)
+	}
+	if len(races) != 2 || races[1].evsPath != "testdata/events_midnight.txt" {
+		t.Errorf("Expected 2 races, got %v", races)
+	}
+
+	if err := os.WriteFile(path, []byte("testdata/config.json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadManifest(path); err == nil {
+		t.Error("Expected error for a line without events path")
+	}
+}
+
+func TestDBAppend_Corrections(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "races.db")
+
+	if err := runDB([]string{"import", "-db", dbPath, "-race", "sprint", "testdata/config.json", "testdata/events.txt"}); err != nil {
+		t.Fatalf("db import error = %v", err)
+	}
+	if err := runDB([]string{"append", "-db", dbPath, "-race", "sprint", "-corrections", "testdata/corrections.txt"}); err != nil {
+		t.Fatalf("db append error = %v", err)
+	}
+
+	store, err := storage.Open(dbPath)
+	if err != nil {
+		t.Fatalf("storage.Open() error = %v", err)
+	}
+	defer store.Close()
+
+	results, err := store.Output("sprint", storage.KIND_RESULT)
+	if err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	_, want, err := runApp(options{cfgPath: "testdata/config.json", evsPath: "testdata/events.txt", correctionsPath: "testdata/corrections.txt"})
+	if err != nil {
+		t.Fatalf("runApp() error = %v", err)
+	}
+	if !equal(results, want) {
+		t.Errorf("Expected stored results %v, got %v", want, results)
+	}
+}
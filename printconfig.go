@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+func runPrintConfig(args []string) error {
+	fs := flag.NewFlagSet("print-config", flag.ContinueOnError)
+	var sets stringList
+	fs.Var(&sets, "set", "override a config value, key=value (repeatable)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: print-config [-set key=value]... <config_path>")
+	}
+
+	rawCfg, err := loadRawConfig(fs.Arg(0), sets)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tVALUE\tSOURCE")
+	for _, f := range rawCfg.Origins() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", f.Field, f.Value, f.Origin)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, env := range rawCfg.IgnoredEnv() {
+		fmt.Printf("warning: %s does not match any config field, ignored\n", env)
+	}
+
+	if _, err := rawCfg.Build(); err != nil {
+		return fmt.Errorf("effective config is invalid: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package processor
+
+import (
+	"biathlon/config"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+const (
+	LOG_FORMAT_TEXT  = "text"
+	LOG_FORMAT_JSONL = "jsonl"
+)
+
+// LogEntry is a single structured line of the output log. EventID and
+// CompetitorID are zero for entries not caused by a race event.
+type LogEntry struct {
+	Time         time.Time
+	Level        slog.Level
+	EventID      int
+	CompetitorID int
+	Message      string
+	Attrs        []slog.Attr
+	// Source is the events file of the event
+	Source string
+
+	// format and args render the message lazily when Message is empty
+	format string
+	args   []any
+}
+
+// Text returns the message, formatting it only when needed.
+func (l LogEntry) Text() string {
+	if l.Message == "" && l.format != "" {
+		return fmt.Sprintf(l.format, l.args...)
+	}
+	return l.Message
+}
+
+// String renders the entry in the text log format: [hh:mm:ss.mmm] message.
+func (l LogEntry) String() string {
+	return "[" + l.Time.Format(config.TIME_FORMAT_WITH_MS) + "] " + l.Text()
+}
+
+// SourcedString renders the entry like String, followed by its events file
+// in brackets.
+func (l LogEntry) SourcedString() string {
+	if l.Source == "" {
+		return l.String()
+	}
+	return l.String() + " [" + l.Source + "]"
+}
+
+// Record converts the entry into a slog record.
+func (l LogEntry) Record() slog.Record {
+	r := slog.NewRecord(l.Time, l.Level, l.Text(), 0)
+	if l.EventID != 0 {
+		r.AddAttrs(slog.Int("eventId", l.EventID))
+	}
+	if l.CompetitorID != 0 {
+		r.AddAttrs(slog.Int("competitorId", l.CompetitorID))
+	}
+	r.AddAttrs(l.Attrs...)
+	if l.Source != "" {
+		r.AddAttrs(slog.String("source", l.Source))
+	}
+	return r
+}
+
+// TextLogs renders entries in the text log format.
+func TextLogs(entries []LogEntry) []string {
+	lines := make([]string, 0, len(entries))
+	for _, l := range entries {
+		lines = append(lines, l.String())
+	}
+	return lines
+}
+
+// EmitLogs passes entries to a slog handler, skipping levels it doesn't
+// handle.
+func EmitLogs(ctx context.Context, h slog.Handler, entries []LogEntry) error {
+	for _, l := range entries {
+		if !h.Enabled(ctx, l.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, l.Record()); err != nil {
+			return fmt.Errorf("failed to handle log entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteJSONLines writes entries as JSON Lines, one object per entry.
+func WriteJSONLines(w io.Writer, entries []LogEntry) error {
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return EmitLogs(context.Background(), h, entries)
+}
@@ -4,18 +4,46 @@ import (
 	"biathlon/competitor"
 	"biathlon/config"
 	"biathlon/event"
+	"biathlon/i18n"
 	"fmt"
+	"io"
+	"log/slog"
+	"slices"
 	"sort"
+	"sync"
 	"time"
 )
 
 const SHOTS_PER_FIRING_LINE = 5
 
+// Processor is safe for one goroutine processing events while others read
+// results and logs through its methods. Competitors and Logs must only be
+// read directly once processing is done. Config, Location, Lang and
+// Registry must not change while events are processed.
 type Processor struct {
 	Config      *config.Config
 	Competitors map[int]*competitor.Competitor
-	Logs        []string
+	Logs        []LogEntry
 	Events      []*event.Event
+
+	// Location is the time zone times are rendered in; the race time zone
+	// by default
+	Location *time.Location
+	// Lang translates log messages and status labels
+	Lang *i18n.Catalog
+	// Registry holds the event types and their handlers
+	Registry *Registry
+	// ShowSource adds the events file to text log lines, for races merged
+	// from several files
+	ShowSource bool
+	// LogSink, when set, receives log entries instead of Logs, so memory
+	// stays bounded on long event streams
+	LogSink func(LogEntry)
+
+	// mu guards the race state; handlers run with it held
+	mu sync.RWMutex
+	// logMu guards Logs and LogSink calls, so handlers can log
+	logMu sync.Mutex
 }
 
 func NewProcessor(cfg *config.Config, events []*event.Event) *Processor {
@@ -23,12 +51,72 @@ func NewProcessor(cfg *config.Config, events []*event.Event) *Processor {
 		Config:      cfg,
 		Competitors: make(map[int]*competitor.Competitor),
 		Events:      events,
+		Location:    cfg.Location,
+		Lang:        i18n.English,
+		Registry:    DefaultRegistry(),
 	}
 }
 
+// AddLog records an informational message not tied to a race event.
 func (p *Processor) AddLog(t time.Time, msg string) {
-	log := fmt.Sprintf("[%s] %s", t.Format(config.TIME_FORMAT_WITH_MS), msg)
-	p.Logs = append(p.Logs, log)
+	p.Log(LogEntry{Time: t, Level: slog.LevelInfo, Message: msg})
+}
+
+// Log records a log entry, moving its time to the output time zone.
+func (p *Processor) Log(entry LogEntry) {
+	entry.Time = p.localTime(entry.Time)
+
+	p.logMu.Lock()
+	defer p.logMu.Unlock()
+	if p.LogSink != nil {
+		p.LogSink(entry)
+		return
+	}
+	p.Logs = append(p.Logs, entry)
+}
+
+// logEventf records an entry for e. The message is formatted from format,
+// already translated, only when the entry is rendered.
+func (p *Processor) logEventf(e *event.Event, level slog.Level, attrs []slog.Attr, format string, args ...any) {
+	p.Log(LogEntry{
+		Time:         e.Time,
+		Level:        level,
+		EventID:      e.EventID,
+		CompetitorID: e.CompetitorID,
+		Attrs:        attrs,
+		Source:       e.Source,
+		format:       format,
+		args:         args,
+	})
+}
+
+// LogEntries returns a copy of the log entries recorded so far.
+func (p *Processor) LogEntries() []LogEntry {
+	p.logMu.Lock()
+	defer p.logMu.Unlock()
+
+	return slices.Clone(p.Logs)
+}
+
+// TextLogs renders the log in the text format.
+func (p *Processor) TextLogs() []string {
+	entries := p.LogEntries()
+	if !p.ShowSource {
+		return TextLogs(entries)
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, l := range entries {
+		lines = append(lines, l.SourcedString())
+	}
+	return lines
+}
+
+func (p *Processor) localTime(t time.Time) time.Time {
+	if p.Location == nil {
+		return t
+	}
+	return t.In(p.Location)
 }
 
 func (p *Processor) getOrCreateCompetitor(id int) *competitor.Competitor {
@@ -45,79 +133,114 @@ func (p *Processor) getOrCreateCompetitor(id int) *competitor.Competitor {
 	return c
 }
 
+// ProcessEvents processes all events of the processor in order.
 func (p *Processor) ProcessEvents() {
+	// Most events log one line
+	p.logMu.Lock()
+	if p.LogSink == nil {
+		p.Logs = slices.Grow(p.Logs, len(p.Events))
+	}
+	p.logMu.Unlock()
 
 	for _, e := range p.Events {
-		comp := p.getOrCreateCompetitor(e.CompetitorID)
-
-		switch e.EventID {
-		case 1: // Registration
-			p.handleRegistration(e, comp)
-
-		case 2: // Start time
-			p.handleStartTime(e, comp)
+		p.Process(e)
+	}
+}
 
-		case 3: // On the start line
-			p.handleOnStartLine(e, comp)
+// ProcessFrom processes events from r as they are read, without keeping
+// them in Events.
+func (p *Processor) ProcessFrom(r event.Reader) error {
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		p.Process(e)
+	}
+}
 
-		case 4: // Started
-			p.handleStarted(e, comp)
+// Process dispatches an event to its registered handler. Events with an
+// unknown ID or invalid parameters are skipped with a warning. Handlers may
+// log, but must not call the result methods of the processor.
+func (p *Processor) Process(e *event.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t, ok := p.Registry.Lookup(e.EventID)
+	if !ok {
+		p.logEventf(e, slog.LevelWarn, nil, p.Lang.T("Unknown event %d of competitor(%d) ignored"), e.EventID, e.CompetitorID)
+		return
+	}
 
-		case 5: // On the firing range
-			p.handleOnFiringRange(e, comp)
+	if err := checkParams(t, e); err != nil {
+		p.logEventf(e, slog.LevelWarn, nil, p.Lang.T("Event %d (%s) of competitor(%d) ignored: %v"), e.EventID, t.Name, e.CompetitorID, err)
+		return
+	}
 
-		case 6: // Hit
-			p.handleHit(e, comp)
+	t.Handle(p, e, p.getOrCreateCompetitor(e.CompetitorID))
+}
 
-		case 7: // Left the firing range
-			p.handleLeftFiringRange(e, comp)
+// checkParams validates the event parameters against the schema of its type
+// and decodes the payload of built-in types. Events from ParseEvent already
+// carry a validated payload.
+func checkParams(t EventType, e *event.Event) error {
+	if e.Payload != nil {
+		return nil
+	}
 
-		case 8: // Entered the penalty lap(s)
-			p.handleEnteredPLaps(e, comp)
+	if err := event.ValidateParams(t.Params, e.ExtraParams); err != nil {
+		return err
+	}
+	return e.DecodePayload()
+}
 
-		case 9: // Left the penalty lap(s)
-			p.handleLeftPLaps(e, comp)
+func (p *Processor) GenerateResults() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
-		case 10: // Ended the main lap
-			p.handleEndedMainLap(e, comp)
+	results := []string{}
 
-		case 11: // Cant continue
-			p.handleCantContinue(e, comp)
-		}
+	for _, c := range p.rankedCompetitors() {
+		results = append(results, p.genCompRes(c))
 	}
-
+	return results
 }
 
-func (p *Processor) GenerateResults() []string {
-
+// rankedCompetitors returns finished competitors by final time, followed by
+// everyone else.
+func (p *Processor) rankedCompetitors() []*competitor.Competitor {
 	comps := []*competitor.Competitor{}
 
 	for _, c := range p.Competitors {
 		comps = append(comps, c)
 	}
 
+	// Map order is random; start from ID order so ties are deterministic
+	sort.Slice(comps, func(i, j int) bool {
+		return comps[i].ID < comps[j].ID
+	})
+
 	sort.SliceStable(comps, func(i, j int) bool {
 		ci, cj := comps[i], comps[j]
 
-		if ci.NotStarted || ci.NotFinished {
+		if ci.NotStarted || ci.NotFinished || ci.Disqualified {
 			return false
 		}
 
-		if cj.NotStarted || cj.NotFinished {
+		if cj.NotStarted || cj.NotFinished || cj.Disqualified {
 			return true
 		}
-		return ci.TotalDuration < cj.TotalDuration
+		return ci.FinalDuration() < cj.FinalDuration()
 	})
 
-	results := []string{}
-
-	for _, c := range comps {
-		results = append(results, p.genCompRes(c))
-	}
-	return results
+	return comps
 }
 
-func formatDuration(d time.Duration) string {
+// FormatDuration renders d as hh:mm:ss.mmm.
+func FormatDuration(d time.Duration) string {
 	hours := int(d.Hours())
 	minutes := int(d.Minutes()) % 60
 	seconds := int(d.Seconds()) % 60
@@ -127,26 +250,28 @@ func formatDuration(d time.Duration) string {
 }
 
 func (p *Processor) handleRegistration(e *event.Event, _ *competitor.Competitor) {
-	log := fmt.Sprintf("The competitor(%d) registered", e.CompetitorID)
-	p.AddLog(e.Time, log)
+	p.logEventf(e, slog.LevelInfo, nil, p.Lang.T("The competitor(%d) registered"), e.CompetitorID)
 }
 
 func (p *Processor) handleStartTime(e *event.Event, comp *competitor.Competitor) {
-	if len(e.ExtraParams) == 1 {
-		startTime := e.ExtraParams[0]
-		parsedStartTime, err := time.Parse(config.TIME_FORMAT_WITH_MS, startTime)
-		comp.CurLapStart = parsedStartTime
-		if err == nil {
-			comp.PlannedStart = parsedStartTime
-			log := fmt.Sprintf("The start time of competitor(%d) was set by a draw to %s", e.CompetitorID, startTime)
-			p.AddLog(e.Time, log)
-		}
+	payload := e.Payload.(event.StartTimePayload)
+
+	startTime := payload.Time
+	if payload.Dated {
+		startTime = config.InLocation(startTime, e.Time.Location())
+	} else {
+		startTime = config.AnchorClock(e.Time, startTime)
 	}
+	comp.CurLapStart = startTime
+	comp.PlannedStart = startTime
+
+	drawn := payload.Params()[0]
+	p.logEventf(e, slog.LevelInfo, []slog.Attr{slog.String("startTime", drawn)},
+		p.Lang.T("The start time of competitor(%d) was set by a draw to %s"), e.CompetitorID, drawn)
 }
 
 func (p *Processor) handleOnStartLine(e *event.Event, _ *competitor.Competitor) {
-	log := fmt.Sprintf("The competitor(%d) is on the start line", e.CompetitorID)
-	p.AddLog(e.Time, log)
+	p.logEventf(e, slog.LevelInfo, nil, p.Lang.T("The competitor(%d) is on the start line"), e.CompetitorID)
 }
 
 func (p *Processor) handleStarted(e *event.Event, comp *competitor.Competitor) {
@@ -155,44 +280,51 @@ func (p *Processor) handleStarted(e *event.Event, comp *competitor.Competitor) {
 	startWindow := comp.PlannedStart.Add(p.Config.StartDelta)
 
 	if comp.ActualStart.After(startWindow) {
-		log := fmt.Sprintf("The competitor(%d) is disqualified", e.CompetitorID)
-		p.AddLog(e.Time, log)
+		p.logEventf(e, slog.LevelWarn, nil, p.Lang.T("The competitor(%d) is disqualified"), e.CompetitorID)
 	} else {
-		log := fmt.Sprintf("The competitor(%d) has started", e.CompetitorID)
 		comp.NotStarted = false
-		p.AddLog(e.Time, log)
+		p.logEventf(e, slog.LevelInfo, nil, p.Lang.T("The competitor(%d) has started"), e.CompetitorID)
 	}
 }
 
 func (p *Processor) handleOnFiringRange(e *event.Event, comp *competitor.Competitor) {
 	comp.CurrentHits = 0
-	if len(e.ExtraParams) == 1 {
-		firingRange := e.ExtraParams[0]
-		log := fmt.Sprintf("The competitor(%d) is on the firing range(%s)", e.CompetitorID, firingRange)
-		p.AddLog(e.Time, log)
-	}
+
+	firingRange := e.Payload.(event.FiringRangePayload).Range
+	p.logEventf(e, slog.LevelInfo, []slog.Attr{slog.Int("firingRange", firingRange)},
+		p.Lang.T("The competitor(%d) is on the firing range(%d)"), e.CompetitorID, firingRange)
 }
 
 func (p *Processor) handleHit(e *event.Event, comp *competitor.Competitor) {
 	comp.TotalHits++
 	comp.CurrentHits++
-	if len(e.ExtraParams) >= 1 {
-		target := e.ExtraParams[0]
-		log := fmt.Sprintf("The target(%s) has been hit by competitor(%d)", target, e.CompetitorID)
-		p.AddLog(e.Time, log)
-	}
+
+	target := e.Payload.(event.TargetPayload).Target
+	p.logEventf(e, slog.LevelInfo, []slog.Attr{slog.Int("target", target)},
+		p.Lang.T("The target(%d) has been hit by competitor(%d)"), target, e.CompetitorID)
 }
 
-func (p *Processor) handleLeftFiringRange(e *event.Event, _ *competitor.Competitor) {
-	log := fmt.Sprintf("The competitor(%d) left the firing range", e.CompetitorID)
-	p.AddLog(e.Time, log)
+func (p *Processor) handleLeftFiringRange(e *event.Event, comp *competitor.Competitor) {
+	comp.EndStage()
+
+	p.logEventf(e, slog.LevelInfo, nil, p.Lang.T("The competitor(%d) left the firing range"), e.CompetitorID)
+
+	// Individual format: every missed shot costs a fixed time penalty
+	missedShots := SHOTS_PER_FIRING_LINE - comp.CurrentHits
+	if p.Config.MissPenalty > 0 && missedShots > 0 {
+		p.addTimePenalty(e, comp, competitor.Penalty{
+			Time:     e.Time,
+			Source:   competitor.PENALTY_SOURCE_MISSED_SHOT,
+			Duration: time.Duration(missedShots) * p.Config.MissPenalty,
+			Reason:   p.Lang.Sprintf("%d missed shot(s)", missedShots),
+		})
+	}
 }
 
 func (p *Processor) handleEnteredPLaps(e *event.Event, comp *competitor.Competitor) {
 	comp.EnterPenalty(e.Time)
 
-	log := fmt.Sprintf("The competitor(%d) entered the penalty laps", e.CompetitorID)
-	p.AddLog(e.Time, log)
+	p.logEventf(e, slog.LevelInfo, nil, p.Lang.T("The competitor(%d) entered the penalty laps"), e.CompetitorID)
 }
 
 func (p *Processor) handleLeftPLaps(e *event.Event, comp *competitor.Competitor) {
@@ -200,8 +332,7 @@ func (p *Processor) handleLeftPLaps(e *event.Event, comp *competitor.Competitor)
 	pLen := missedShots * p.Config.PenaltyLen
 	comp.ExitPenalty(e.Time, pLen)
 
-	log := fmt.Sprintf("The competitor(%d) left the penalty laps", e.CompetitorID)
-	p.AddLog(e.Time, log)
+	p.logEventf(e, slog.LevelInfo, nil, p.Lang.T("The competitor(%d) left the penalty laps"), e.CompetitorID)
 }
 
 func (p *Processor) handleEndedMainLap(e *event.Event, comp *competitor.Competitor) {
@@ -213,19 +344,60 @@ func (p *Processor) handleEndedMainLap(e *event.Event, comp *competitor.Competit
 		comp.FinishTime = e.Time
 	}
 
-	log := fmt.Sprintf("The competitor(%d) ended the main lap", e.CompetitorID)
-	p.AddLog(e.Time, log)
+	p.logEventf(e, slog.LevelInfo, nil, p.Lang.T("The competitor(%d) ended the main lap"), e.CompetitorID)
 }
 
-func (p *Processor) handleCantContinue(e *event.Event, _ *competitor.Competitor) {
+func (p *Processor) handleCantContinue(e *event.Event, comp *competitor.Competitor) {
+	comp.DnfReason = e.Payload.(event.ReasonPayload).Text
+
 	comment := ""
-	if len(e.ExtraParams) > 0 {
-		for _, word := range e.ExtraParams {
-			comment += word + " "
-		}
+	if comp.DnfReason != "" {
+		comment = comp.DnfReason + " "
 	}
-	log := fmt.Sprintf("The competitor can`t continue: %s", comment)
-	p.AddLog(e.Time, log)
+	p.logEventf(e, slog.LevelWarn, []slog.Attr{slog.String("reason", comp.DnfReason)},
+		p.Lang.T("The competitor can`t continue: %s"), comment)
+}
+
+func (p *Processor) handleTimePenalty(e *event.Event, comp *competitor.Competitor) {
+	payload := e.Payload.(event.TimePenaltyPayload)
+
+	p.addTimePenalty(e, comp, competitor.Penalty{
+		Time:     e.Time,
+		Source:   competitor.PENALTY_SOURCE_JURY,
+		Duration: payload.Duration,
+		Reason:   payload.Reason,
+	})
+}
+
+func (p *Processor) addTimePenalty(e *event.Event, comp *competitor.Competitor, pen competitor.Penalty) {
+	comp.AddTimePenalty(pen)
+
+	format := p.Lang.T("The competitor(%d) received a time penalty of %s (%s)")
+	args := []any{e.CompetitorID, FormatDuration(pen.Duration), p.Lang.T(pen.Source)}
+	if pen.Reason != "" {
+		format += ": %s"
+		args = append(args, pen.Reason)
+	}
+
+	attrs := []slog.Attr{
+		slog.String("penaltySource", pen.Source),
+		slog.Int64("penaltyMs", pen.Duration.Milliseconds()),
+		slog.String("reason", pen.Reason),
+	}
+	p.logEventf(e, slog.LevelInfo, attrs, format, args...)
+}
+
+func (p *Processor) handleDisqualified(e *event.Event, comp *competitor.Competitor) {
+	reason := e.Payload.(event.ReasonPayload).Text
+	comp.Disqualify(reason)
+
+	format := p.Lang.T("The competitor(%d) is disqualified")
+	args := []any{e.CompetitorID}
+	if reason != "" {
+		format += ": %s"
+		args = append(args, reason)
+	}
+	p.logEventf(e, slog.LevelWarn, []slog.Attr{slog.String("reason", reason)}, format, args...)
 }
 
 func (p *Processor) parseMainLaps(c *competitor.Competitor) string {
@@ -233,12 +405,12 @@ func (p *Processor) parseMainLaps(c *competitor.Competitor) string {
 	if len(c.LapDurations) > 0 {
 		d := c.LapDurations[0]
 		avgSpeed := float64(p.Config.LapLen) / d.Seconds()
-		res += fmt.Sprintf("{%s, %.3f}", formatDuration(d), avgSpeed)
+		res += fmt.Sprintf("{%s, %.3f}", FormatDuration(d), avgSpeed)
 
 		if len(c.LapDurations) > 1 {
 			for _, d := range c.LapDurations[1:] {
 				avgSpeed := float64(p.Config.LapLen) / d.Seconds()
-				res += fmt.Sprintf(", {%s, %.3f}", formatDuration(d), avgSpeed)
+				res += fmt.Sprintf(", {%s, %.3f}", FormatDuration(d), avgSpeed)
 			}
 		}
 	}
@@ -259,14 +431,17 @@ func (p *Processor) parseMainLaps(c *competitor.Competitor) string {
 func (p *Processor) parseTimeAndStatus(c *competitor.Competitor) string {
 	res := ""
 	switch {
+	case c.Disqualified:
+		res += "[" + p.Lang.T(STATUS_DISQUALIFIED) + "] "
+
 	case c.NotStarted:
-		res += "[NotStarted] "
+		res += "[" + p.Lang.T(STATUS_NOT_STARTED) + "] "
 
 	case c.NotFinished:
-		res += "[NotFinished] "
+		res += "[" + p.Lang.T(STATUS_NOT_FINISHED) + "] "
 
 	default:
-		res += fmt.Sprintf("[%v] ", formatDuration(c.TotalDuration))
+		res += fmt.Sprintf("[%v] ", FormatDuration(c.FinalDuration()))
 	}
 	return res
 }
@@ -275,7 +450,7 @@ func (p *Processor) parsePLaps(c *competitor.Competitor) string {
 	res := ""
 	if c.TotalPenaltyLen > 0 {
 		avgPenSpeed := float64(c.TotalPenaltyLen) / c.TotalPenaltyTime.Seconds()
-		res += fmt.Sprintf("{%s, %.3f} ", formatDuration(c.TotalPenaltyTime), avgPenSpeed)
+		res += fmt.Sprintf("{%s, %.3f} ", FormatDuration(c.TotalPenaltyTime), avgPenSpeed)
 	} else {
 		res += "{,} "
 	}
@@ -287,6 +462,24 @@ func (p *Processor) parseHitsAndShots(c *competitor.Competitor) string {
 	return fmt.Sprintf("%d/%d", c.TotalHits, totalShots)
 }
 
+// parseTimePenalties shows raw time and penalties separately; it is empty
+// for competitors without time penalties.
+func (p *Processor) parseTimePenalties(c *competitor.Competitor) string {
+	if len(c.Penalties) == 0 {
+		return ""
+	}
+
+	res := " (" + p.Lang.Sprintf("raw %s + penalty %s", FormatDuration(c.TotalDuration), FormatDuration(c.PenaltyDuration()))
+	for _, pen := range c.Penalties {
+		res += fmt.Sprintf("; %s %s", p.Lang.T(pen.Source), FormatDuration(pen.Duration))
+		if pen.Reason != "" {
+			res += ": " + pen.Reason
+		}
+	}
+	res += ")"
+	return res
+}
+
 func (p *Processor) parseID(c *competitor.Competitor) string {
 	return fmt.Sprintf("%d ", c.ID)
 }
@@ -304,5 +497,7 @@ func (p *Processor) genCompRes(c *competitor.Competitor) string {
 
 	res += p.parseHitsAndShots(c)
 
+	res += p.parseTimePenalties(c)
+
 	return res
 }
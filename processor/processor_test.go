@@ -1,8 +1,16 @@
 package processor
 
 import (
+	"biathlon/competitor"
 	"biathlon/config"
 	"biathlon/event"
+	"biathlon/simulator"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -21,8 +29,8 @@ func TestAddLog(t *testing.T) {
 	}
 
 	expectedLog := "[12:00:00.000] Test log message"
-	if p.Logs[0] != expectedLog {
-		t.Errorf("Expected log '%s', got '%s'", expectedLog, p.Logs[0])
+	if p.TextLogs()[0] != expectedLog {
+		t.Errorf("Expected log '%s', got '%s'", expectedLog, p.TextLogs()[0])
 	}
 }
 
@@ -61,3 +69,426 @@ func TestProcessEvents(t *testing.T) {
 		t.Errorf("Expected Competitor PlannedStart to be set, got zero value")
 	}
 }
+
+func TestTimePenalties(t *testing.T) {
+	base := time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		Laps:        1,
+		LapLen:      1000,
+		FiringLines: 1,
+		StartDelta:  time.Minute,
+		MissPenalty: time.Minute,
+	}
+	events := []*event.Event{
+		{CompetitorID: 1, EventID: 2, ExtraParams: []string{"10:00:00.000"}, Time: base},
+		{CompetitorID: 1, EventID: 4, Time: base},
+		{CompetitorID: 1, EventID: 5, ExtraParams: []string{"1"}, Time: base.Add(time.Minute)},
+		{CompetitorID: 1, EventID: 6, ExtraParams: []string{"1"}, Time: base.Add(time.Minute)},
+		{CompetitorID: 1, EventID: 6, ExtraParams: []string{"2"}, Time: base.Add(time.Minute)},
+		{CompetitorID: 1, EventID: 6, ExtraParams: []string{"3"}, Time: base.Add(time.Minute)},
+		{CompetitorID: 1, EventID: 7, Time: base.Add(2 * time.Minute)},
+		{CompetitorID: 1, EventID: 10, Time: base.Add(5 * time.Minute)},
+		{CompetitorID: 1, EventID: 12, ExtraParams: []string{"00:00:30", "Jury", "sanction"}, Time: base.Add(6 * time.Minute)},
+	}
+	p := NewProcessor(cfg, events)
+
+	p.ProcessEvents()
+
+	comp := p.Competitors[1]
+	if len(comp.Penalties) != 2 {
+		t.Fatalf("Expected 2 penalties, got %d", len(comp.Penalties))
+	}
+	if comp.FinalDuration() != 7*time.Minute+30*time.Second {
+		t.Errorf("Expected final duration 7m30s, got %v", comp.FinalDuration())
+	}
+
+	expected := "[00:07:30.000] 1 [{00:05:00.000, 3.333}] {,} 3/5 (raw 00:05:00.000 + penalty 00:02:30.000; missed shot 00:02:00.000: 2 missed shot(s); jury 00:00:30.000: Jury sanction)"
+	if res := p.GenerateResults(); len(res) != 1 || res[0] != expected {
+		t.Errorf("Expected results %q, got %q", expected, res)
+	}
+}
+
+func TestResults(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	base := time.Date(2025, 1, 10, 10, 0, 0, 0, oslo)
+	cfg := &config.Config{Laps: 1, LapLen: 1000, FiringLines: 1, StartDelta: time.Minute, Location: oslo}
+	events := []*event.Event{
+		{CompetitorID: 1, EventID: 2, ExtraParams: []string{"10:00:00.000"}, Time: base},
+		{CompetitorID: 1, EventID: 4, Time: base},
+		{CompetitorID: 1, EventID: 10, Time: base.Add(5 * time.Minute)},
+		{CompetitorID: 2, EventID: 1, Time: base},
+	}
+	p := NewProcessor(cfg, events)
+	p.Location = time.UTC
+
+	p.ProcessEvents()
+	results := p.Results()
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	first := results[0]
+	if first.Rank != 1 || first.Status != STATUS_FINISHED || first.FinalTimeMs != 300000 {
+		t.Errorf("Unexpected first result %+v", first)
+	}
+	if first.FinishTime == nil || first.FinishTime.Location() != time.UTC || first.FinishTime.Hour() != 9 {
+		t.Errorf("Expected finish time 09:05 UTC, got %v", first.FinishTime)
+	}
+
+	if results[1].Rank != 0 || results[1].Status != STATUS_NOT_STARTED {
+		t.Errorf("Unexpected second result %+v", results[1])
+	}
+}
+
+func TestStructuredLogs(t *testing.T) {
+	base := time.Date(2025, 2, 14, 10, 0, 0, 0, time.UTC)
+	events := []*event.Event{
+		{CompetitorID: 3, EventID: 5, ExtraParams: []string{"2"}, Time: base, Source: "range.txt"},
+		{CompetitorID: 3, EventID: 11, ExtraParams: []string{"Lost", "ski"}, Time: base.Add(time.Minute)},
+	}
+	p := NewProcessor(&config.Config{}, events)
+	p.ProcessEvents()
+
+	if len(p.Logs) != 2 {
+		t.Fatalf("Expected 2 log entries, got %d", len(p.Logs))
+	}
+
+	entry := p.Logs[0]
+	if entry.EventID != 5 || entry.CompetitorID != 3 || entry.Level != slog.LevelInfo {
+		t.Errorf("Expected info entry for event 5 of competitor 3, got %+v", entry)
+	}
+	if p.Logs[1].Level != slog.LevelWarn {
+		t.Errorf("Expected can't continue to be a warning, got %v", p.Logs[1].Level)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSONLines(&buf, p.Logs); err != nil {
+		t.Fatalf("WriteJSONLines() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 JSON lines, got %d", len(lines))
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", lines[0], err)
+	}
+	expected := map[string]any{
+		"time":         "2025-02-14T10:00:00Z",
+		"level":        "INFO",
+		"msg":          "The competitor(3) is on the firing range(2)",
+		"eventId":      float64(5),
+		"competitorId": float64(3),
+		"firingRange":  float64(2),
+		"source":       "range.txt",
+	}
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("Expected %s %v, got %v", k, v, got[k])
+		}
+	}
+
+	buf.Reset()
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})
+	if err := EmitLogs(context.Background(), h, p.Logs); err != nil {
+		t.Fatalf("EmitLogs() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `reason="Lost ski"`) || strings.Contains(buf.String(), "firing range") {
+		t.Errorf("Expected only the warning to be emitted, got %q", buf.String())
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	base := time.Date(2025, 2, 14, 10, 0, 0, 0, time.UTC)
+	events := []*event.Event{
+		{CompetitorID: 1, EventID: 40, ExtraParams: []string{"skis"}, Time: base},
+		{CompetitorID: 1, EventID: 99, Time: base},
+		{CompetitorID: 1, EventID: event.EVENT_HIT, ExtraParams: []string{"x"}, Time: base},
+	}
+	p := NewProcessor(&config.Config{}, events)
+
+	checked := 0
+	err := p.Registry.Register(EventType{
+		ID:     40,
+		Name:   "Equipment check",
+		Params: []event.Param{{Name: "item", Kind: event.PARAM_TEXT}},
+		Handle: func(p *Processor, e *event.Event, _ *competitor.Competitor) {
+			checked++
+			p.AddLog(e.Time, "Checked "+e.ExtraParams[0])
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if err := p.Registry.Register(EventType{ID: 40, Name: "Bib change", Handle: func(*Processor, *event.Event, *competitor.Competitor) {}}); err == nil {
+		t.Error("Expected error when registering a duplicate ID")
+	}
+
+	p.ProcessEvents()
+
+	if checked != 1 {
+		t.Errorf("Expected custom handler to run once, got %d", checked)
+	}
+
+	expected := []string{
+		"[10:00:00.000] Checked skis",
+		"[10:00:00.000] Unknown event 99 of competitor(1) ignored",
+		`[10:00:00.000] Event 6 (Hit) of competitor(1) ignored: invalid target "x": expected int`,
+	}
+	logs := p.TextLogs()
+	if len(logs) != len(expected) {
+		t.Fatalf("Expected %d logs, got %d: %q", len(expected), len(logs), logs)
+	}
+	for i := range expected {
+		if logs[i] != expected[i] {
+			t.Errorf("Expected log %q, got %q", expected[i], logs[i])
+		}
+	}
+
+	if p.Logs[1].Level != slog.LevelWarn || p.Logs[2].Level != slog.LevelWarn {
+		t.Error("Expected unknown and invalid events to be logged as warnings")
+	}
+	if p.Competitors[1].TotalHits != 0 {
+		t.Errorf("Expected invalid hit to be ignored, got %d hits", p.Competitors[1].TotalHits)
+	}
+}
+
+func TestProcessFrom(t *testing.T) {
+	events, err := event.LoadEvents("../testdata/events_midnight.txt")
+	if err != nil {
+		t.Fatalf("LoadEvents() error = %v", err)
+	}
+	cfg, err := config.LoadConfig("../testdata/config_midnight.json")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := event.WriteBinaryEvents(&buf, events); err != nil {
+		t.Fatalf("WriteBinaryEvents() error = %v", err)
+	}
+	br, err := event.NewBinaryReader(&buf)
+	if err != nil {
+		t.Fatalf("NewBinaryReader() error = %v", err)
+	}
+
+	streamed := NewProcessor(cfg, nil)
+	if err := streamed.ProcessFrom(event.ResolveDatesFrom(br, cfg.Start)); err != nil {
+		t.Fatalf("ProcessFrom() error = %v", err)
+	}
+
+	event.ResolveDates(events, cfg.Start)
+	loaded := NewProcessor(cfg, events)
+	loaded.ProcessEvents()
+
+	got, want := streamed.GenerateResults(), loaded.GenerateResults()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected results %q, got %q", want, got)
+	}
+	if len(streamed.Logs) != len(loaded.Logs) {
+		t.Errorf("Expected %d logs, got %d", len(loaded.Logs), len(streamed.Logs))
+	}
+}
+
+func benchConfig() *config.Config {
+	return &config.Config{
+		Laps:        3,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC),
+		StartDelta:  90 * time.Second,
+		Location:    time.UTC,
+	}
+}
+
+// benchEvents returns a simulated race with n competitors as text lines.
+func benchEvents(b testing.TB, n int) []byte {
+	sim := simulator.NewSimulator(benchConfig(), 1)
+	var buf bytes.Buffer
+	if err := event.WriteEvents(&buf, sim.Generate(sim.RandomProfiles(n))); err != nil {
+		b.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// readBenchEvents parses simulated events and places them on their days;
+// a large simulated race lasts more than a day.
+func readBenchEvents(tb testing.TB, data []byte, source string) []*event.Event {
+	events, err := event.ReadAll(event.NewTextReader(bytes.NewReader(data), source))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	event.ResolveDates(events, benchConfig().Start)
+	return events
+}
+
+func TestBenchEvents_ValidRace(t *testing.T) {
+	p := NewProcessor(benchConfig(), readBenchEvents(t, benchEvents(t, 2000), "bench"))
+	p.ProcessEvents()
+
+	for _, c := range p.Competitors {
+		for _, d := range c.LapDurations {
+			if d < 0 {
+				t.Fatalf("Expected non-negative lap durations, got %v for competitor %d", d, c.ID)
+			}
+		}
+	}
+}
+
+func BenchmarkProcessEvents(b *testing.B) {
+	data := benchEvents(b, 2000)
+	events := readBenchEvents(b, data, "bench")
+	b.ReportMetric(float64(len(events)), "events/op")
+	b.ReportAllocs()
+
+	for b.Loop() {
+		p := NewProcessor(benchConfig(), events)
+		p.ProcessEvents()
+		p.GenerateResults()
+	}
+}
+
+func BenchmarkProcessEvents_RenderLogs(b *testing.B) {
+	data := benchEvents(b, 2000)
+	events := readBenchEvents(b, data, "bench")
+	b.ReportAllocs()
+
+	for b.Loop() {
+		p := NewProcessor(benchConfig(), events)
+		p.ProcessEvents()
+		p.TextLogs()
+	}
+}
+
+// BenchmarkProcessFrom_Streaming parses and processes events without
+// keeping events or logs in memory.
+func BenchmarkProcessFrom_Streaming(b *testing.B) {
+	data := benchEvents(b, 2000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		tr := event.NewTextReader(bytes.NewReader(data), "bench")
+		tr.Reuse = true
+
+		p := NewProcessor(benchConfig(), nil)
+		p.LogSink = func(LogEntry) {}
+		if err := p.ProcessFrom(event.ResolveDatesFrom(tr, benchConfig().Start)); err != nil {
+			b.Fatal(err)
+		}
+		p.GenerateResults()
+	}
+}
+
+func TestProcessFrom_Reuse(t *testing.T) {
+	data := benchEvents(t, 50)
+
+	events := readBenchEvents(t, data, "sim")
+	loaded := NewProcessor(benchConfig(), events)
+	loaded.ProcessEvents()
+
+	tr := event.NewTextReader(bytes.NewReader(data), "sim")
+	tr.Reuse = true
+	streamed := NewProcessor(benchConfig(), nil)
+
+	var logs []string
+	streamed.LogSink = func(l LogEntry) { logs = append(logs, l.String()) }
+	if err := streamed.ProcessFrom(event.ResolveDatesFrom(tr, benchConfig().Start)); err != nil {
+		t.Fatalf("ProcessFrom() error = %v", err)
+	}
+
+	if len(streamed.Logs) != 0 {
+		t.Errorf("Expected no kept logs with a sink, got %d", len(streamed.Logs))
+	}
+	if strings.Join(logs, "\n") != strings.Join(loaded.TextLogs(), "\n") {
+		t.Error("Expected streamed logs to match logs of loaded events")
+	}
+	if strings.Join(streamed.GenerateResults(), "\n") != strings.Join(loaded.GenerateResults(), "\n") {
+		t.Error("Expected streamed results to match results of loaded events")
+	}
+}
+
+// TestProcessor_Concurrent reads results and logs while events are being
+// processed; run with -race to check synchronization.
+func TestProcessor_Concurrent(t *testing.T) {
+	data := benchEvents(t, 50)
+
+	events := readBenchEvents(t, data, "sim")
+	serial := NewProcessor(benchConfig(), events)
+	serial.ProcessEvents()
+
+	live := NewProcessor(benchConfig(), nil)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		tr := event.NewTextReader(bytes.NewReader(data), "sim")
+		if err := live.ProcessFrom(event.ResolveDatesFrom(tr, benchConfig().Start)); err != nil {
+			t.Errorf("ProcessFrom() error = %v", err)
+		}
+	}()
+
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				for _, r := range live.Results() {
+					if r.Hits > r.Shots {
+						t.Errorf("Expected at most %d hits, got %d", r.Shots, r.Hits)
+					}
+				}
+				live.GenerateResults()
+				live.TextLogs()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(live.LogEntries()) != len(serial.Logs) {
+		t.Errorf("Expected %d logs, got %d", len(serial.Logs), len(live.LogEntries()))
+	}
+	if strings.Join(live.GenerateResults(), "\n") != strings.Join(serial.GenerateResults(), "\n") {
+		t.Error("Expected results of concurrent processing to match serial processing")
+	}
+}
+
+func TestProcessor_ConcurrentLog(t *testing.T) {
+	p := NewProcessor(benchConfig(), nil)
+	testTime := time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				p.AddLog(testTime, "note")
+				p.Process(&event.Event{Time: testTime, EventID: event.EVENT_REGISTRATION, CompetitorID: 1})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(p.LogEntries()); got != 1600 {
+		t.Errorf("Expected 1600 logs, got %d", got)
+	}
+}
@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"biathlon/competitor"
+	"biathlon/event"
+	"fmt"
+)
+
+// HandlerFunc applies an event to the race state.
+type HandlerFunc func(p *Processor, e *event.Event, comp *competitor.Competitor)
+
+// EventType is an event type the processor knows how to handle.
+type EventType struct {
+	ID     int
+	Name   string
+	Params []event.Param
+	Handle HandlerFunc
+}
+
+// Registry maps event IDs to their types.
+type Registry struct {
+	types map[int]EventType
+}
+
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[int]EventType)}
+}
+
+// Register adds an event type. IDs must be unique.
+func (r *Registry) Register(t EventType) error {
+	if t.Handle == nil {
+		return fmt.Errorf("event type %d (%s) has no handler", t.ID, t.Name)
+	}
+	if existing, ok := r.types[t.ID]; ok {
+		return fmt.Errorf("event ID %d is already registered as %s", t.ID, existing.Name)
+	}
+
+	r.types[t.ID] = t
+	return nil
+}
+
+func (r *Registry) Lookup(id int) (EventType, bool) {
+	t, ok := r.types[id]
+	return t, ok
+}
+
+// DefaultRegistry returns a registry with the built-in event types.
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, t := range builtinTypes {
+		if err := r.Register(t); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// builtin returns a built-in event type with its name and parameter schema
+// from the event package.
+func builtin(id int, handle HandlerFunc) EventType {
+	t, _ := event.BuiltinType(id)
+	return EventType{ID: id, Name: t.Name, Params: t.Params, Handle: handle}
+}
+
+var builtinTypes = []EventType{
+	builtin(event.EVENT_REGISTRATION, (*Processor).handleRegistration),
+	builtin(event.EVENT_START_TIME, (*Processor).handleStartTime),
+	builtin(event.EVENT_ON_START_LINE, (*Processor).handleOnStartLine),
+	builtin(event.EVENT_STARTED, (*Processor).handleStarted),
+	builtin(event.EVENT_ON_FIRING_RANGE, (*Processor).handleOnFiringRange),
+	builtin(event.EVENT_HIT, (*Processor).handleHit),
+	builtin(event.EVENT_LEFT_FIRING_RANGE, (*Processor).handleLeftFiringRange),
+	builtin(event.EVENT_ENTERED_PENALTY, (*Processor).handleEnteredPLaps),
+	builtin(event.EVENT_LEFT_PENALTY, (*Processor).handleLeftPLaps),
+	builtin(event.EVENT_ENDED_MAIN_LAP, (*Processor).handleEndedMainLap),
+	builtin(event.EVENT_CANT_CONTINUE, (*Processor).handleCantContinue),
+	builtin(event.EVENT_TIME_PENALTY, (*Processor).handleTimePenalty),
+	builtin(event.EVENT_DISQUALIFIED, (*Processor).handleDisqualified),
+}
@@ -0,0 +1,145 @@
+package processor
+
+import (
+	"biathlon/competitor"
+	"time"
+)
+
+const (
+	STATUS_FINISHED     = "Finished"
+	STATUS_NOT_STARTED  = "NotStarted"
+	STATUS_NOT_FINISHED = "NotFinished"
+	STATUS_DISQUALIFIED = "Disqualified"
+)
+
+// Result is the structured result of a single competitor. Times are
+// rendered in the processor's Location and marshal to ISO-8601.
+type Result struct {
+	Rank         int           `json:"rank,omitempty"`
+	CompetitorID int           `json:"competitorId"`
+	Status       string        `json:"status"`
+	Reason       string        `json:"reason,omitempty"`
+	PlannedStart *time.Time    `json:"plannedStart,omitempty"`
+	ActualStart  *time.Time    `json:"actualStart,omitempty"`
+	FinishTime   *time.Time    `json:"finishTime,omitempty"`
+	RawTimeMs    int64         `json:"rawTimeMs"`
+	PenaltyMs    int64         `json:"penaltyTimeMs"`
+	FinalTimeMs  int64         `json:"finalTimeMs"`
+	Laps         []LapResult   `json:"laps"`
+	PenaltyLaps  LapResult     `json:"penaltyLaps"`
+	Hits         int           `json:"hits"`
+	Shots        int           `json:"shots"`
+	Stages       []Stage       `json:"stages"`
+	Penalties    []TimePenalty `json:"penalties,omitempty"`
+}
+
+type LapResult struct {
+	DurationMs int64   `json:"durationMs"`
+	Speed      float64 `json:"speed"`
+}
+
+// Stage is the shooting result of a single firing line visit.
+type Stage struct {
+	Hits  int `json:"hits"`
+	Shots int `json:"shots"`
+}
+
+type TimePenalty struct {
+	Time       time.Time `json:"time"`
+	Source     string    `json:"source"`
+	DurationMs int64     `json:"durationMs"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+// Results returns structured results in the same order as GenerateResults.
+func (p *Processor) Results() []Result {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	results := []Result{}
+
+	rank := 0
+	for _, c := range p.rankedCompetitors() {
+		r := p.compResult(c)
+		if r.Status == STATUS_FINISHED {
+			rank++
+			r.Rank = rank
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func (p *Processor) compResult(c *competitor.Competitor) Result {
+	r := Result{
+		CompetitorID: c.ID,
+		Status:       status(c),
+		PlannedStart: p.optionalTime(c.PlannedStart),
+		ActualStart:  p.optionalTime(c.ActualStart),
+		FinishTime:   p.optionalTime(c.FinishTime),
+		RawTimeMs:    c.TotalDuration.Milliseconds(),
+		PenaltyMs:    c.PenaltyDuration().Milliseconds(),
+		FinalTimeMs:  c.FinalDuration().Milliseconds(),
+		Laps:         []LapResult{},
+		Stages:       []Stage{},
+		Hits:         c.TotalHits,
+		Shots:        SHOTS_PER_FIRING_LINE * p.Config.FiringLines,
+	}
+
+	for _, d := range c.LapDurations {
+		r.Laps = append(r.Laps, LapResult{
+			DurationMs: d.Milliseconds(),
+			Speed:      float64(p.Config.LapLen) / d.Seconds(),
+		})
+	}
+
+	switch r.Status {
+	case STATUS_DISQUALIFIED:
+		r.Reason = c.DsqReason
+	case STATUS_NOT_FINISHED:
+		r.Reason = c.DnfReason
+	}
+
+	for _, hits := range c.StageHits {
+		r.Stages = append(r.Stages, Stage{Hits: hits, Shots: SHOTS_PER_FIRING_LINE})
+	}
+
+	if c.TotalPenaltyLen > 0 {
+		r.PenaltyLaps = LapResult{
+			DurationMs: c.TotalPenaltyTime.Milliseconds(),
+			Speed:      float64(c.TotalPenaltyLen) / c.TotalPenaltyTime.Seconds(),
+		}
+	}
+
+	for _, pen := range c.Penalties {
+		r.Penalties = append(r.Penalties, TimePenalty{
+			Time:       p.localTime(pen.Time),
+			Source:     pen.Source,
+			DurationMs: pen.Duration.Milliseconds(),
+			Reason:     pen.Reason,
+		})
+	}
+
+	return r
+}
+
+func status(c *competitor.Competitor) string {
+	switch {
+	case c.Disqualified:
+		return STATUS_DISQUALIFIED
+	case c.NotStarted:
+		return STATUS_NOT_STARTED
+	case c.NotFinished:
+		return STATUS_NOT_FINISHED
+	default:
+		return STATUS_FINISHED
+	}
+}
+
+func (p *Processor) optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	local := p.localTime(t)
+	return &local
+}
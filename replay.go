@@ -0,0 +1,46 @@
+package main
+
+import (
+	"biathlon/replay"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	speed := fs.Float64("speed", 1, "replay speed multiplier (0 emits without waiting)")
+	out := fs.String("out", "", "write events to file instead of stdout")
+	url := fs.String("url", "", "post events to HTTP endpoint instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: replay [-speed N] [-out path | -url URL] <events_path>")
+	}
+	if *out != "" && *url != "" {
+		return fmt.Errorf("-out and -url are mutually exclusive")
+	}
+
+	entries, err := replay.LoadEntries(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("error loading events: %v", err)
+	}
+
+	var sink replay.Sink = &replay.WriterSink{W: os.Stdout}
+	switch {
+	case *out != "":
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		defer file.Close()
+		sink = &replay.WriterSink{W: file}
+
+	case *url != "":
+		sink = &replay.HTTPSink{URL: *url}
+	}
+
+	return replay.NewReplayer(*speed, sink).Replay(entries)
+}
@@ -0,0 +1,153 @@
+package replay
+
+import (
+	"biathlon/event"
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Entry is a single event together with the line it was read from,
+// so replayed output matches the original file byte for byte.
+type Entry struct {
+	Event *event.Event
+	Line  string
+}
+
+type Sink interface {
+	Emit(line string) error
+}
+
+type WriterSink struct {
+	W io.Writer
+}
+
+func (s *WriterSink) Emit(line string) error {
+	_, err := fmt.Fprintln(s.W, line)
+	return err
+}
+
+// HTTPSink posts every line as a separate text/plain request.
+type HTTPSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *HTTPSink) Emit(line string) error {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Post(s.URL, "text/plain", strings.NewReader(line+"\n"))
+	if err != nil {
+		return fmt.Errorf("failed to post event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to post event: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+type Replayer struct {
+	Speed float64
+	Sink  Sink
+	sleep func(time.Duration)
+}
+
+// NewReplayer creates a replayer emitting to sink at the given speed.
+// Speed 1 replays in real time, 2 twice as fast; 0 disables waiting.
+func NewReplayer(speed float64, sink Sink) *Replayer {
+	return &Replayer{
+		Speed: speed,
+		Sink:  sink,
+		sleep: time.Sleep,
+	}
+}
+
+func (r *Replayer) Replay(entries []Entry) error {
+	var prev time.Time
+
+	for i, e := range entries {
+		if i > 0 && r.Speed > 0 {
+			if gap := e.Event.Time.Sub(prev); gap > 0 {
+				r.sleep(time.Duration(float64(gap) / r.Speed))
+			}
+		}
+		prev = e.Event.Time
+
+		if err := r.Sink.Emit(e.Line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadEntries reads a text or binary events file. Events of binary files
+// are replayed as their canonical text lines.
+func LoadEntries(filename string) ([]Entry, error) {
+	f, err := event.OpenEvents(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	if f.Binary() {
+		events, err := event.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			entries = append(entries, Entry{Event: e, Line: e.String()})
+		}
+	} else {
+		entries, err = loadTextEntries(filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	events := make([]*event.Event, len(entries))
+	for i := range entries {
+		events[i] = entries[i].Event
+	}
+	event.ResolveDates(events, time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	return entries, nil
+}
+
+func loadTextEntries(filename string) ([]Entry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		e, err := event.ParseEvent(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse line '%s': %v", line, err)
+		}
+		entries = append(entries, Entry{Event: e, Line: line})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
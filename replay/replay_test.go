@@ -0,0 +1,110 @@
+package replay
+
+import (
+	"biathlon/event"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testEntries() []Entry {
+	base := time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)
+	return []Entry{
+		{Event: &event.Event{Time: base, EventID: 1, CompetitorID: 1}, Line: "[10:00:00.000] 1 1"},
+		{Event: &event.Event{Time: base.Add(10 * time.Second), EventID: 1, CompetitorID: 2}, Line: "[10:00:10.000] 1 2"},
+		{Event: &event.Event{Time: base.Add(30 * time.Second), EventID: 3, CompetitorID: 1}, Line: "[10:00:30.000] 3 1"},
+	}
+}
+
+func TestReplay_Speed(t *testing.T) {
+	var buf bytes.Buffer
+	var slept []time.Duration
+
+	r := NewReplayer(10, &WriterSink{W: &buf})
+	r.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	if err := r.Replay(testEntries()); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Second}
+	if len(slept) != len(expected) {
+		t.Fatalf("Expected %d waits, got %d", len(expected), len(slept))
+	}
+	for i := range expected {
+		if slept[i] != expected[i] {
+			t.Errorf("Expected wait %v, got %v", expected[i], slept[i])
+		}
+	}
+
+	want := "[10:00:00.000] 1 1\n[10:00:10.000] 1 2\n[10:00:30.000] 3 1\n"
+	if buf.String() != want {
+		t.Errorf("Expected output %q, got %q", want, buf.String())
+	}
+}
+
+func TestReplay_NoWait(t *testing.T) {
+	r := NewReplayer(0, &WriterSink{W: io.Discard})
+	r.sleep = func(d time.Duration) { t.Errorf("Unexpected wait %v", d) }
+
+	if err := r.Replay(testEntries()); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		got = append(got, string(body))
+	}))
+	defer srv.Close()
+
+	r := NewReplayer(0, &HTTPSink{URL: srv.URL})
+	if err := r.Replay(testEntries()); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	if len(got) != 3 || got[0] != "[10:00:00.000] 1 1\n" {
+		t.Errorf("Unexpected requests: %q", got)
+	}
+}
+
+func TestLoadEntries_FileNotFound(t *testing.T) {
+	if _, err := LoadEntries("nonexistent_events"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
+
+func TestLoadEntries_Binary(t *testing.T) {
+	text, err := LoadEntries("../testdata/events_midnight.txt")
+	if err != nil {
+		t.Fatalf("LoadEntries() error = %v", err)
+	}
+
+	events, err := event.LoadEvents("../testdata/events_midnight.txt")
+	if err != nil {
+		t.Fatalf("LoadEvents() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "events.bin")
+	if err := event.WriteBinaryEventsFile(path, events); err != nil {
+		t.Fatalf("WriteBinaryEventsFile() error = %v", err)
+	}
+
+	binary, err := LoadEntries(path)
+	if err != nil {
+		t.Fatalf("LoadEntries() error = %v", err)
+	}
+	if len(binary) != len(text) {
+		t.Fatalf("Expected %d entries, got %d", len(text), len(binary))
+	}
+	for i := range text {
+		if binary[i].Line != text[i].Line || !binary[i].Event.Time.Equal(text[i].Event.Time) {
+			t.Errorf("Entry %d: expected %q, got %q", i, text[i].Line, binary[i].Line)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+	"biathlon/config"
+	"biathlon/i18n"
+	"biathlon/processor"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+//go:embed templates/results.html
+var htmlTemplate string
+
+// Page is the data rendered into a results report.
+type Page struct {
+	Title   string
+	Config  *config.Config
+	Results []processor.Result
+	// Lang translates headers and status labels; English when nil
+	Lang *i18n.Catalog
+}
+
+// T translates a header or label into the page language.
+func (page Page) T(msg string) string {
+	return page.Lang.T(msg)
+}
+
+// Language returns the page language code.
+func (page Page) Language() string {
+	if page.Lang == nil {
+		return i18n.LANG_EN
+	}
+	return page.Lang.Lang
+}
+
+var funcs = template.FuncMap{
+	"duration": formatMs,
+	"speed":    func(v float64) string { return fmt.Sprintf("%.3f", v) },
+	"clock":    formatClock,
+	"stages":   formatStages,
+	"date":     formatDate,
+	"inc":      func(i int) int { return i + 1 },
+	"iterate":  func(n int) []struct{} { return make([]struct{}, n) },
+	"miss":     func(s processor.Stage) int { return s.Shots - s.Hits },
+}
+
+var htmlTmpl = template.Must(template.New("results").Funcs(funcs).Parse(htmlTemplate))
+
+// WriteHTML renders a single self-contained HTML results page.
+func WriteHTML(w io.Writer, page Page) error {
+	return htmlTmpl.Execute(w, page)
+}
+
+func WriteHTMLFile(path string, page Page) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteHTML(file, page); err != nil {
+		return fmt.Errorf("failed to render html: %w", err)
+	}
+	return nil
+}
+
+func formatMs(ms int64) string {
+	return processor.FormatDuration(time.Duration(ms) * time.Millisecond)
+}
+
+func formatClock(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(config.TIME_FORMAT_WITH_MS)
+}
+
+// formatStages renders hits per firing line, e.g. "4+5".
+func formatStages(stages []processor.Stage) string {
+	parts := make([]string, len(stages))
+	for i, s := range stages {
+		parts[i] = fmt.Sprint(s.Hits)
+	}
+	return strings.Join(parts, "+")
+}
+
+func formatDate(cfg *config.Config) string {
+	if cfg.Date.Year() == 0 {
+		return ""
+	}
+	return cfg.Date.Format(config.DATE_FORMAT)
+}
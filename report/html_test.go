@@ -0,0 +1,89 @@
+package report
+
+import (
+	"biathlon/config"
+	"biathlon/i18n"
+	"biathlon/processor"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testPage() Page {
+	finish := time.Date(2025, 2, 14, 10, 30, 0, 0, time.UTC)
+	return Page{
+		Title:  "Sprint",
+		Config: &config.Config{Laps: 2, LapLen: 3500, PenaltyLen: 150, FiringLines: 2, Date: time.Date(2025, 2, 14, 0, 0, 0, 0, time.UTC)},
+		Results: []processor.Result{
+			{
+				Rank:         1,
+				CompetitorID: 7,
+				Status:       processor.STATUS_FINISHED,
+				FinishTime:   &finish,
+				RawTimeMs:    1500000,
+				PenaltyMs:    60000,
+				FinalTimeMs:  1560000,
+				Laps:         []processor.LapResult{{DurationMs: 750000, Speed: 4.667}, {DurationMs: 750000, Speed: 4.667}},
+				Hits:         9,
+				Shots:        10,
+				Stages:       []processor.Stage{{Hits: 5, Shots: 5}, {Hits: 4, Shots: 5}},
+				Penalties:    []processor.TimePenalty{{Source: "jury", DurationMs: 60000, Reason: "Unsporting behaviour"}},
+			},
+			{
+				CompetitorID: 3,
+				Status:       processor.STATUS_NOT_STARTED,
+				Laps:         []processor.LapResult{},
+				Shots:        10,
+			},
+		},
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := WriteHTML(&buf, testPage()); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	html := buf.String()
+
+	expected := []string{
+		"<title>Sprint</title>",
+		"Date: 2025-02-14",
+		`id="competitor-7"`,
+		`id="competitor-3"`,
+		"00:26:00.000",
+		"00:12:30.000",
+		"5&#43;4",
+		"Unsporting behaviour",
+		"NotStarted",
+		"<script>",
+	}
+	for _, s := range expected {
+		if !strings.Contains(html, s) {
+			t.Errorf("Expected html to contain %q", s)
+		}
+	}
+
+	if strings.Contains(html, "<link") || strings.Contains(html, "src=") {
+		t.Error("Expected a self-contained page without external resources")
+	}
+}
+
+func TestWriteHTML_Localized(t *testing.T) {
+	page := testPage()
+	page.Lang, _ = i18n.Lookup(i18n.LANG_NO)
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, page); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	html := buf.String()
+
+	for _, s := range []string{`<html lang="no">`, "Startnr.", "Ikke startet", "Løpere"} {
+		if !strings.Contains(html, s) {
+			t.Errorf("Expected html to contain %q", s)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package report
+
+import (
+	"biathlon/i18n"
+	"biathlon/processor"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	TABLE_TEXT     = "text"
+	TABLE_MARKDOWN = "markdown"
+)
+
+// Column is a single column of a results table.
+type Column struct {
+	Name   string
+	Header string
+	Right  bool
+	// Label columns hold labels translated like the headers
+	Label bool
+	Value func(r processor.Result) string
+}
+
+var columns = []Column{
+	{Name: "rank", Header: "Rank", Right: true, Value: func(r processor.Result) string {
+		if r.Rank == 0 {
+			return ""
+		}
+		return fmt.Sprint(r.Rank)
+	}},
+	{Name: "bib", Header: "Bib", Right: true, Value: func(r processor.Result) string {
+		return fmt.Sprint(r.CompetitorID)
+	}},
+	{Name: "status", Header: "Status", Label: true, Value: func(r processor.Result) string {
+		return r.Status
+	}},
+	{Name: "time", Header: "Time", Right: true, Value: func(r processor.Result) string {
+		if r.Rank == 0 {
+			return ""
+		}
+		return formatMs(r.FinalTimeMs)
+	}},
+	{Name: "raw", Header: "Raw time", Right: true, Value: func(r processor.Result) string {
+		if r.Rank == 0 {
+			return ""
+		}
+		return formatMs(r.RawTimeMs)
+	}},
+	{Name: "penalty", Header: "Time penalty", Right: true, Value: func(r processor.Result) string {
+		if r.PenaltyMs == 0 {
+			return ""
+		}
+		return formatMs(r.PenaltyMs)
+	}},
+	{Name: "laps", Header: "Laps", Value: func(r processor.Result) string {
+		return formatLaps(r.Laps)
+	}},
+	{Name: "penaltyLaps", Header: "Penalty laps", Right: true, Value: func(r processor.Result) string {
+		if r.PenaltyLaps.DurationMs == 0 {
+			return ""
+		}
+		return formatMs(r.PenaltyLaps.DurationMs)
+	}},
+	{Name: "shooting", Header: "Shooting", Value: func(r processor.Result) string {
+		return formatStages(r.Stages)
+	}},
+	{Name: "hits", Header: "Hits", Right: true, Value: func(r processor.Result) string {
+		return fmt.Sprintf("%d/%d", r.Hits, r.Shots)
+	}},
+	{Name: "reason", Header: "Reason", Value: func(r processor.Result) string {
+		return r.Reason
+	}},
+}
+
+// DEFAULT_COLUMNS are shown when no column selection is given.
+var DEFAULT_COLUMNS = []string{"rank", "bib", "status", "time", "laps", "penaltyLaps", "shooting", "hits"}
+
+// ColumnNames lists all available column names.
+func ColumnNames() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// SelectColumns returns the columns with the given names in that order.
+func SelectColumns(names []string) ([]Column, error) {
+	if len(names) == 0 {
+		names = DEFAULT_COLUMNS
+	}
+
+	selected := make([]Column, 0, len(names))
+	for _, name := range names {
+		col, ok := findColumn(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q, available: %s", name, strings.Join(ColumnNames(), ", "))
+		}
+		selected = append(selected, col)
+	}
+	return selected, nil
+}
+
+func findColumn(name string) (Column, bool) {
+	for _, c := range columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// RenderTable renders results as lines of a text table with aligned
+// columns or as a GitHub-flavored Markdown table. Headers and status labels
+// are translated with lang.
+func RenderTable(format string, results []processor.Result, cols []Column, lang *i18n.Catalog) ([]string, error) {
+	cells := make([][]string, 0, len(results)+1)
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = lang.T(c.Header)
+	}
+	cells = append(cells, header)
+
+	for _, r := range results {
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = c.Value(r)
+			if c.Label {
+				row[i] = lang.T(row[i])
+			}
+		}
+		cells = append(cells, row)
+	}
+
+	switch format {
+	case TABLE_TEXT:
+		return renderText(cells, cols), nil
+	case TABLE_MARKDOWN:
+		return renderMarkdown(cells, cols), nil
+	}
+	return nil, fmt.Errorf("unknown table format %q, expected %s or %s", format, TABLE_TEXT, TABLE_MARKDOWN)
+}
+
+func columnWidths(cells [][]string) []int {
+	widths := make([]int, len(cells[0]))
+	for _, row := range cells {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	return widths
+}
+
+func pad(s string, width int, right bool) string {
+	fill := strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s)))
+	if right {
+		return fill + s
+	}
+	return s + fill
+}
+
+func renderText(cells [][]string, cols []Column) []string {
+	widths := columnWidths(cells)
+
+	line := func(row []string) string {
+		parts := make([]string, len(row))
+		for i, cell := range row {
+			parts[i] = pad(cell, widths[i], cols[i].Right)
+		}
+		return strings.TrimRight(strings.Join(parts, "  "), " ")
+	}
+
+	rule := make([]string, len(widths))
+	for i, w := range widths {
+		rule[i] = strings.Repeat("-", w)
+	}
+
+	lines := []string{line(cells[0]), strings.Join(rule, "  ")}
+	for _, row := range cells[1:] {
+		lines = append(lines, line(row))
+	}
+	return lines
+}
+
+var markdownEscaper = strings.NewReplacer(`|`, `\|`)
+
+func renderMarkdown(cells [][]string, cols []Column) []string {
+	// Widths must count the escape characters
+	escaped := make([][]string, len(cells))
+	for i, row := range cells {
+		escaped[i] = make([]string, len(row))
+		for j, cell := range row {
+			escaped[i][j] = markdownEscaper.Replace(cell)
+		}
+	}
+	cells = escaped
+	widths := columnWidths(cells)
+
+	line := func(row []string) string {
+		parts := make([]string, len(row))
+		for i, cell := range row {
+			parts[i] = pad(cell, widths[i], cols[i].Right)
+		}
+		return "| " + strings.Join(parts, " | ") + " |"
+	}
+
+	align := make([]string, len(widths))
+	for i, w := range widths {
+		dashes := strings.Repeat("-", max(w, 3)-1)
+		if cols[i].Right {
+			align[i] = dashes + ":"
+		} else {
+			align[i] = ":" + dashes
+		}
+		align[i] = pad(align[i], w, false)
+	}
+
+	lines := []string{line(cells[0]), "| " + strings.Join(align, " | ") + " |"}
+	for _, row := range cells[1:] {
+		lines = append(lines, line(row))
+	}
+	return lines
+}
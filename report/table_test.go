@@ -0,0 +1,102 @@
+package report
+
+import (
+	"biathlon/processor"
+	"strings"
+	"testing"
+)
+
+func TestRenderTable_Text(t *testing.T) {
+	cols, err := SelectColumns([]string{"rank", "bib", "status", "time"})
+	if err != nil {
+		t.Fatalf("SelectColumns() error = %v", err)
+	}
+
+	lines, err := RenderTable(TABLE_TEXT, testPage().Results, cols, nil)
+	if err != nil {
+		t.Fatalf("RenderTable() error = %v", err)
+	}
+
+	expected := []string{
+		"Rank  Bib  Status              Time",
+		"----  ---  ----------  ------------",
+		"   1    7  Finished    00:26:00.000",
+		"        3  NotStarted",
+	}
+
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %q", len(expected), len(lines), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestRenderTable_Markdown(t *testing.T) {
+	cols, err := SelectColumns([]string{"bib", "shooting"})
+	if err != nil {
+		t.Fatalf("SelectColumns() error = %v", err)
+	}
+
+	lines, err := RenderTable(TABLE_MARKDOWN, testPage().Results, cols, nil)
+	if err != nil {
+		t.Fatalf("RenderTable() error = %v", err)
+	}
+
+	expected := []string{
+		"| Bib | Shooting |",
+		"| --: | :------- |",
+		"|   7 | 5+4      |",
+		"|   3 |          |",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected table:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+func TestRenderTable_MarkdownPipe(t *testing.T) {
+	cols, err := SelectColumns([]string{"bib", "reason"})
+	if err != nil {
+		t.Fatalf("SelectColumns() error = %v", err)
+	}
+
+	results := []processor.Result{
+		{CompetitorID: 1, Status: processor.STATUS_DISQUALIFIED, Reason: "Rule 3|4 breach"},
+		{CompetitorID: 2, Status: processor.STATUS_NOT_STARTED},
+	}
+
+	lines, err := RenderTable(TABLE_MARKDOWN, results, cols, nil)
+	if err != nil {
+		t.Fatalf("RenderTable() error = %v", err)
+	}
+
+	expected := []string{
+		"| Bib | Reason           |",
+		"| --: | :--------------- |",
+		"|   1 | Rule 3\\|4 breach |",
+		"|   2 |                  |",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected table:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	cols, err := SelectColumns(nil)
+	if err != nil {
+		t.Fatalf("SelectColumns() error = %v", err)
+	}
+	if len(cols) != len(DEFAULT_COLUMNS) {
+		t.Errorf("Expected %d default columns, got %d", len(DEFAULT_COLUMNS), len(cols))
+	}
+
+	if _, err := SelectColumns([]string{"rank", "nope"}); err == nil {
+		t.Error("Expected error for unknown column")
+	}
+
+	if _, err := RenderTable("csv", nil, cols, nil); err == nil {
+		t.Error("Expected error for unknown table format")
+	}
+}
@@ -0,0 +1,62 @@
+// Official results sheet, compile with: typst compile results.typ
+#set page(paper: "a4", margin: 2cm, footer: context [
+  #set text(8pt)
+  {{title .}}
+  #h(1fr)
+  {{.T "Page"}} #counter(page).display("1 / 1", both: true)
+])
+#set text(size: 9pt, lang: "{{.Language}}")
+
+#align(center)[
+  #text(16pt, weight: "bold")[{{title .}}] \
+{{- if or (date .Config) .Config.Venue}}
+  {{date .Config}}{{if and (date .Config) .Config.Venue}} · {{end}}{{esc .Config.Venue}} \
+{{- end}}
+  {{.Config.Laps}} × {{.Config.LapLen}} m · {{.Config.FiringLines}} {{.T "firing line(s)"}} · {{.T "penalty lap"}} {{.Config.PenaltyLen}} m
+]
+
+== {{.T "Official results"}}
+
+#table(
+  columns: (auto, auto, auto, auto, 1fr, auto, auto),
+  stroke: (x: none, y: 0.5pt + gray),
+  align: (right, right, right, right, left, left, right),
+  table.header([*{{.T "Rank"}}*], [*{{.T "Bib"}}*], [*{{.T "Time"}}*], [*{{.T "Behind"}}*], [*{{.T "Laps"}}*], [*{{.T "Shooting"}}*], [*{{.T "Hits"}}*]),
+{{- $leader := leader .Results}}
+{{- range ranked .Results}}
+  [{{.Rank}}], [{{.CompetitorID}}], [{{duration .FinalTimeMs}}{{if .PenaltyMs}}#super[1]{{end}}], [{{if gt .FinalTimeMs $leader}}+{{duration (sub .FinalTimeMs $leader)}}{{end}}], [{{laps .Laps}}], [{{stages .Stages}}], [{{.Hits}}/{{.Shots}}],
+{{- end}}
+)
+{{with unranked .Results}}
+== {{$.T "Not classified"}}
+
+#table(
+  columns: (auto, auto, 1fr),
+  stroke: (x: none, y: 0.5pt + gray),
+  table.header([*{{$.T "Bib"}}*], [*{{$.T "Status"}}*], [*{{$.T "Reason"}}*]),
+{{- range .}}
+  [{{.CompetitorID}}], [{{$.T (status .Status)}}], [{{esc .Reason}}],
+{{- end}}
+)
+{{end}}
+#v(1em)
+#set text(size: 8pt)
+{{.T "Times include time penalties. Shooting shows hits per firing line."}} \
+{{.T "DNS: did not start. DNF: did not finish. DSQ: disqualified."}}
+{{- if penalized .Results}} \
+#super[1] {{.T "Includes time penalties:"}}
+{{- range ranked .Results}}{{$id := .CompetitorID}}{{range .Penalties}} {{$.T "bib"}} {{$id}} +{{duration .DurationMs}} ({{esc ($.T .Source)}}{{with .Reason}}: {{esc .}}{{end}});{{end}}{{end}}
+{{- end}}
+{{with .Config.Jury}}
+#v(2em)
+#set text(size: 9pt)
+*{{$.T "Jury"}}*
+
+#grid(
+  columns: (1fr, 1fr),
+  row-gutter: 2.5em,
+{{- range .}}
+  [{{esc .}}], [{{$.T "Signature"}}: #box(width: 1fr, repeat[.])],
+{{- end}}
+)
+{{end}}
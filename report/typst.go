@@ -0,0 +1,124 @@
+package report
+
+import (
+	"biathlon/processor"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/results.typ
+var typstTemplate string
+
+var typstFuncs = template.FuncMap{
+	"duration":  formatMs,
+	"stages":    formatStages,
+	"date":      formatDate,
+	"esc":       escapeTypst,
+	"title":     pageTitle,
+	"laps":      formatLaps,
+	"leader":    leaderTimeMs,
+	"ranked":    ranked,
+	"unranked":  unranked,
+	"penalized": penalized,
+	"status":    shortStatus,
+	"sub":       func(a, b int64) int64 { return a - b },
+}
+
+var typstTmpl = template.Must(template.New("results.typ").Funcs(typstFuncs).Parse(typstTemplate))
+
+// WriteTypst renders a printable official results sheet as typst source.
+// Compile it to PDF with `typst compile`.
+func WriteTypst(w io.Writer, page Page) error {
+	return typstTmpl.Execute(w, page)
+}
+
+func WriteTypstFile(path string, page Page) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteTypst(file, page); err != nil {
+		return fmt.Errorf("failed to render typst: %w", err)
+	}
+	return nil
+}
+
+var typstEscaper = strings.NewReplacer(
+	`\`, `\\`, `#`, `\#`, `[`, `\[`, `]`, `\]`, `*`, `\*`, `_`, `\_`,
+	"`", "\\`", `$`, `\$`, `<`, `\<`, `>`, `\>`, `@`, `\@`, `/`, `\/`,
+)
+
+func escapeTypst(s string) string {
+	return typstEscaper.Replace(s)
+}
+
+func pageTitle(page Page) string {
+	if page.Config.Name != "" {
+		return escapeTypst(page.Config.Name)
+	}
+	return escapeTypst(page.Title)
+}
+
+func formatLaps(laps []processor.LapResult) string {
+	parts := make([]string, len(laps))
+	for i, l := range laps {
+		parts[i] = formatMs(l.DurationMs)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func leaderTimeMs(results []processor.Result) int64 {
+	for _, r := range results {
+		if r.Rank == 1 {
+			return r.FinalTimeMs
+		}
+	}
+	return 0
+}
+
+func ranked(results []processor.Result) []processor.Result {
+	var res []processor.Result
+	for _, r := range results {
+		if r.Rank > 0 {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func unranked(results []processor.Result) []processor.Result {
+	var res []processor.Result
+	for _, r := range results {
+		if r.Rank == 0 {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func penalized(results []processor.Result) bool {
+	for _, r := range ranked(results) {
+		if r.PenaltyMs > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func shortStatus(status string) string {
+	switch status {
+	case processor.STATUS_NOT_STARTED:
+		return "DNS"
+	case processor.STATUS_NOT_FINISHED:
+		return "DNF"
+	case processor.STATUS_DISQUALIFIED:
+		return "DSQ"
+	}
+	return status
+}
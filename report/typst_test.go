@@ -0,0 +1,103 @@
+package report
+
+import (
+	"biathlon/i18n"
+	"biathlon/processor"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTypst(t *testing.T) {
+	page := testPage()
+	page.Config.Name = "Club Sprint #1"
+	page.Config.Venue = "Raubichi"
+	page.Config.Jury = []string{"Chief: A. Ivanov"}
+	page.Results = append(page.Results, processor.Result{
+		CompetitorID: 9,
+		Status:       processor.STATUS_DISQUALIFIED,
+		Reason:       "Skipped [penalty] lap",
+	})
+
+	var buf bytes.Buffer
+	if err := WriteTypst(&buf, page); err != nil {
+		t.Fatalf("WriteTypst() error = %v", err)
+	}
+	src := buf.String()
+
+	expected := []string{
+		`Club Sprint \#1`,
+		"2025-02-14 · Raubichi",
+		"[1], [7], [00:26:00.000#super[1]]",
+		"[3], [DNS], []",
+		`[9], [DSQ], [Skipped \[penalty\] lap]`,
+		"bib 7 +00:01:00.000 (jury: Unsporting behaviour)",
+		"[Chief: A. Ivanov], [Signature:",
+	}
+	for _, s := range expected {
+		if !strings.Contains(src, s) {
+			t.Errorf("Expected typst source to contain %q", s)
+		}
+	}
+}
+
+func TestWriteTypst_Header(t *testing.T) {
+	tests := []struct {
+		name     string
+		date     time.Time
+		venue    string
+		expected string
+	}{
+		{name: "no date or venue", date: time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), expected: `[Sprint] \
+  2 × 3500 m`},
+		{name: "venue only", date: time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), venue: "Raubichi", expected: `[Sprint] \
+  Raubichi \
+  2 × 3500 m`},
+		{name: "date only", date: time.Date(2025, 2, 14, 0, 0, 0, 0, time.UTC), expected: `[Sprint] \
+  2025-02-14 \
+  2 × 3500 m`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := testPage()
+			page.Config.Date = tt.date
+			page.Config.Venue = tt.venue
+
+			var buf bytes.Buffer
+			if err := WriteTypst(&buf, page); err != nil {
+				t.Fatalf("WriteTypst() error = %v", err)
+			}
+			if src := buf.String(); !strings.Contains(src, tt.expected) {
+				t.Errorf("Expected typst header %q, got %q", tt.expected, src[:strings.Index(src, "==")])
+			}
+		})
+	}
+}
+
+func TestWriteTypst_Localized(t *testing.T) {
+	page := testPage()
+	page.Lang, _ = i18n.Lookup(i18n.LANG_RU)
+
+	var buf bytes.Buffer
+	if err := WriteTypst(&buf, page); err != nil {
+		t.Fatalf("WriteTypst() error = %v", err)
+	}
+	src := buf.String()
+
+	for _, s := range []string{`lang: "ru"`, "Страница #counter(page)", "Официальные результаты"} {
+		if !strings.Contains(src, s) {
+			t.Errorf("Expected typst source to contain %q", s)
+		}
+	}
+	if strings.Contains(src, "Page #counter") {
+		t.Errorf("Expected the page footer to be translated")
+	}
+}
+
+func TestEscapeTypst(t *testing.T) {
+	if got := escapeTypst("#1 [a] *b* $c"); got != `\#1 \[a\] \*b\* \$c` {
+		t.Errorf("Unexpected escaping: %s", got)
+	}
+}
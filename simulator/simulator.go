@@ -0,0 +1,166 @@
+package simulator
+
+import (
+	"biathlon/config"
+	"biathlon/event"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const SHOTS_PER_FIRING_LINE = 5
+
+const (
+	registrationLead = 30 * time.Minute
+	drawLead         = 5 * time.Minute
+	startLineLead    = 15 * time.Second
+	shotInterval     = 1500 * time.Millisecond
+	penaltyEntryGap  = 5 * time.Second
+)
+
+// Profile describes how a simulated competitor skis and shoots.
+type Profile struct {
+	CompetitorID   int
+	SkiSpeed       float64       // meters per second
+	Accuracy       float64       // probability of hitting a single target
+	RangeTime      time.Duration // time spent on the firing range
+	DNFProbability float64       // probability of not finishing the race
+}
+
+type Simulator struct {
+	Config *config.Config
+	rnd    *rand.Rand
+}
+
+func NewSimulator(cfg *config.Config, seed int64) *Simulator {
+	return &Simulator{
+		Config: cfg,
+		rnd:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// RandomProfiles returns n profiles with plausible random characteristics.
+func (s *Simulator) RandomProfiles(n int) []Profile {
+	profiles := make([]Profile, 0, n)
+	for i := 1; i <= n; i++ {
+		profiles = append(profiles, Profile{
+			CompetitorID:   i,
+			SkiSpeed:       4.5 + s.rnd.Float64()*2,
+			Accuracy:       0.6 + s.rnd.Float64()*0.4,
+			RangeTime:      time.Duration(20+s.rnd.Intn(20)) * time.Second,
+			DNFProbability: 0.05,
+		})
+	}
+	return profiles
+}
+
+// Generate builds a complete, time-ordered event stream for the given profiles.
+// Competitors start in the order of profiles, one StartDelta apart.
+func (s *Simulator) Generate(profiles []Profile) []*event.Event {
+	events := []*event.Event{}
+
+	for i, pr := range profiles {
+		events = append(events, s.generateCompetitor(i, pr)...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events
+}
+
+func (s *Simulator) generateCompetitor(idx int, pr Profile) []*event.Event {
+	cfg := s.Config
+	id := pr.CompetitorID
+	evs := []*event.Event{}
+
+	add := func(t time.Time, eventID int, params ...string) {
+		evs = append(evs, &event.Event{
+			Time:         t.Truncate(time.Millisecond),
+			EventID:      eventID,
+			CompetitorID: id,
+			ExtraParams:  params,
+		})
+	}
+
+	planned := cfg.Start.Add(time.Duration(idx) * cfg.StartDelta)
+
+	add(cfg.Start.Add(-registrationLead+time.Duration(idx)*time.Second), 1)
+	add(cfg.Start.Add(-drawLead+time.Duration(idx)*time.Second), 2, planned.Format(config.TIME_FORMAT_WITH_MS))
+	add(planned.Add(-startLineLead), 3)
+
+	started := planned.Add(s.jitter(cfg.StartDelta / 2))
+	add(started, 4)
+
+	dnfLap := -1
+	if s.rnd.Float64() < pr.DNFProbability {
+		dnfLap = s.rnd.Intn(cfg.Laps)
+	}
+
+	cur := started
+	for lap := 0; lap < cfg.Laps; lap++ {
+		lapTime := s.skiTime(float64(cfg.LapLen), pr.SkiSpeed)
+
+		if lap == dnfLap {
+			add(cur.Add(s.jitter(lapTime)), 11, "Lost", "in", "the", "forest")
+			return evs
+		}
+
+		cur = cur.Add(lapTime / 2)
+		if lap < cfg.FiringLines {
+			cur = s.shoot(cur, lap+1, pr, add)
+		}
+		cur = cur.Add(lapTime - lapTime/2)
+		add(cur, 10)
+	}
+
+	return evs
+}
+
+// shoot simulates a visit to the firing range and the penalty laps that
+// follow it, returning the time the competitor is back on the course.
+func (s *Simulator) shoot(t time.Time, line int, pr Profile, add func(time.Time, int, ...string)) time.Time {
+	add(t, 5, fmt.Sprint(line))
+
+	shotTime := t
+	hits := 0
+	for target := 1; target <= SHOTS_PER_FIRING_LINE; target++ {
+		shotTime = shotTime.Add(shotInterval)
+		if s.rnd.Float64() < pr.Accuracy {
+			hits++
+			add(shotTime, 6, fmt.Sprint(target))
+		}
+	}
+
+	left := t.Add(pr.RangeTime)
+	if !left.After(shotTime) {
+		left = shotTime.Add(shotInterval)
+	}
+	add(left, 7)
+
+	misses := SHOTS_PER_FIRING_LINE - hits
+	if misses == 0 {
+		return left
+	}
+
+	entered := left.Add(penaltyEntryGap)
+	add(entered, 8)
+	exited := entered.Add(s.skiTime(float64(misses*s.Config.PenaltyLen), pr.SkiSpeed))
+	add(exited, 9)
+	return exited
+}
+
+// skiTime returns the time needed to cover dist meters at speed with a
+// small random variation.
+func (s *Simulator) skiTime(dist, speed float64) time.Duration {
+	variation := 0.95 + s.rnd.Float64()*0.1
+	return time.Duration(dist / speed * variation * float64(time.Second))
+}
+
+func (s *Simulator) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(s.rnd.Int63n(int64(max)))
+}
@@ -0,0 +1,110 @@
+package simulator
+
+import (
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/processor"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Laps:        2,
+		LapLen:      3500,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC),
+		StartDelta:  90 * time.Second,
+	}
+}
+
+func TestGenerate_TimeOrdered(t *testing.T) {
+	sim := NewSimulator(testConfig(), 1)
+	events := sim.Generate(sim.RandomProfiles(20))
+
+	if len(events) == 0 {
+		t.Fatal("Expected events, got none")
+	}
+
+	for i := 1; i < len(events); i++ {
+		if events[i].Time.Before(events[i-1].Time) {
+			t.Fatalf("Events not ordered at %d: %v before %v", i, events[i].Time, events[i-1].Time)
+		}
+	}
+}
+
+func TestGenerate_AllEventTypes(t *testing.T) {
+	sim := NewSimulator(testConfig(), 1)
+	profiles := []Profile{
+		{CompetitorID: 1, SkiSpeed: 5, Accuracy: 0.5, RangeTime: 30 * time.Second},
+		{CompetitorID: 2, SkiSpeed: 5, Accuracy: 1, RangeTime: 30 * time.Second, DNFProbability: 1},
+	}
+
+	seen := map[int]bool{}
+	for _, e := range sim.Generate(profiles) {
+		seen[e.EventID] = true
+	}
+
+	for id := 1; id <= 11; id++ {
+		if !seen[id] {
+			t.Errorf("Expected event type %d to be generated", id)
+		}
+	}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	var a, b bytes.Buffer
+
+	simA := NewSimulator(testConfig(), 42)
+	if err := event.WriteEvents(&a, simA.Generate(simA.RandomProfiles(5))); err != nil {
+		t.Fatalf("WriteEvents() error = %v", err)
+	}
+
+	simB := NewSimulator(testConfig(), 42)
+	if err := event.WriteEvents(&b, simB.Generate(simB.RandomProfiles(5))); err != nil {
+		t.Fatalf("WriteEvents() error = %v", err)
+	}
+
+	if a.String() != b.String() {
+		t.Error("Expected identical output for identical seeds")
+	}
+}
+
+func TestGenerate_Parsable(t *testing.T) {
+	cfg := testConfig()
+	sim := NewSimulator(cfg, 7)
+	profiles := []Profile{
+		{CompetitorID: 1, SkiSpeed: 5, Accuracy: 1, RangeTime: 30 * time.Second},
+	}
+
+	var buf bytes.Buffer
+	if err := event.WriteEvents(&buf, sim.Generate(profiles)); err != nil {
+		t.Fatalf("WriteEvents() error = %v", err)
+	}
+
+	var events []*event.Event
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		e, err := event.ParseEvent(line)
+		if err != nil {
+			t.Fatalf("ParseEvent(%q) error = %v", line, err)
+		}
+		events = append(events, e)
+	}
+
+	p := processor.NewProcessor(cfg, events)
+	p.ProcessEvents()
+
+	comp := p.Competitors[1]
+	if comp == nil {
+		t.Fatal("Expected Competitor with ID 1, got nil")
+	}
+	if comp.NotStarted || comp.NotFinished {
+		t.Errorf("Expected competitor to finish, got NotStarted=%v NotFinished=%v", comp.NotStarted, comp.NotFinished)
+	}
+	if comp.TotalHits != SHOTS_PER_FIRING_LINE*cfg.FiringLines {
+		t.Errorf("Expected %d hits, got %d", SHOTS_PER_FIRING_LINE*cfg.FiringLines, comp.TotalHits)
+	}
+}
@@ -0,0 +1,380 @@
+package storage
+
+import (
+	"biathlon/config"
+	"biathlon/corrections"
+	"biathlon/event"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+var ErrRaceNotFound = errors.New("race not found")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS races (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	name       TEXT NOT NULL UNIQUE,
+	config     TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS events (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	race_id       INTEGER NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+	time          TEXT NOT NULL,
+	event_id      INTEGER NOT NULL,
+	competitor_id INTEGER NOT NULL,
+	params        TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS corrections (
+	id      INTEGER PRIMARY KEY AUTOINCREMENT,
+	race_id INTEGER NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+	line    INTEGER NOT NULL,
+	text    TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS results (
+	race_id  INTEGER NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+	kind     TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	line     TEXT NOT NULL,
+	PRIMARY KEY (race_id, kind, position)
+);
+`
+
+// TIME_FORMAT stores event times in UTC with millisecond precision, so that
+// they sort as text and keep their instant whatever the race time zone.
+const TIME_FORMAT = "2006-01-02T15:04:05.000Z07:00"
+
+// Result kinds stored in the results table.
+const (
+	KIND_LOG    = "log"
+	KIND_RESULT = "result"
+)
+
+// Store is an embedded SQLite database holding races, their configs,
+// raw events, corrections and the last computed logs and results.
+type Store struct {
+	db *sql.DB
+}
+
+type Race struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// CreateRace stores a new race with its raw JSON config and events. Events
+// should have their dates resolved, see event.ResolveDates.
+func (s *Store) CreateRace(name string, rawCfg []byte, events []*event.Event) (int64, error) {
+	if _, err := config.ParseConfig(rawCfg); err != nil {
+		return 0, fmt.Errorf("invalid config: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		"INSERT INTO races (name, config, created_at) VALUES (?, ?, ?)",
+		name, string(rawCfg), time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create race: %w", err)
+	}
+
+	raceID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertEvents(tx, raceID, events); err != nil {
+		return 0, err
+	}
+
+	return raceID, tx.Commit()
+}
+
+// AppendEvents adds events (e.g. corrections) to an existing race.
+func (s *Store) AppendEvents(name string, events []*event.Event) error {
+	race, err := s.Race(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertEvents(tx, race.ID, events); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AppendCorrections adds corrections to an existing race. They are stored
+// as written and applied in the order they were added.
+func (s *Store) AppendCorrections(name string, corrs []*corrections.Correction) error {
+	race, err := s.Race(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range corrs {
+		_, err := tx.Exec(
+			"INSERT INTO corrections (race_id, line, text) VALUES (?, ?, ?)",
+			race.ID, c.Line, c.Text,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store correction: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Corrections returns the corrections of a race in the order they were
+// added. Their dates are not resolved, see corrections.ResolveDates.
+func (s *Store) Corrections(name string) ([]*corrections.Correction, error) {
+	race, err := s.Race(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query("SELECT line, text FROM corrections WHERE race_id = ? ORDER BY id", race.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var corrs []*corrections.Correction
+	for rows.Next() {
+		var line int
+		var text string
+		if err := rows.Scan(&line, &text); err != nil {
+			return nil, err
+		}
+
+		c, err := corrections.ParseCorrection(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stored correction '%s': %v", text, err)
+		}
+		c.Line = line
+		corrs = append(corrs, c)
+	}
+	return corrs, rows.Err()
+}
+
+func insertEvents(tx *sql.Tx, raceID int64, events []*event.Event) error {
+	stmt, err := tx.Prepare(
+		"INSERT INTO events (race_id, time, event_id, competitor_id, params) VALUES (?, ?, ?, ?, ?)",
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range events {
+		_, err := stmt.Exec(
+			raceID,
+			e.Time.UTC().Format(TIME_FORMAT),
+			e.EventID,
+			e.CompetitorID,
+			strings.Join(e.ExtraParams, " "),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store event: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) Race(name string) (*Race, error) {
+	var r Race
+	var created string
+
+	err := s.db.QueryRow("SELECT id, name, created_at FROM races WHERE name = ?", name).
+		Scan(&r.ID, &r.Name, &created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrRaceNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.CreatedAt, _ = time.Parse(time.RFC3339, created)
+	return &r, nil
+}
+
+func (s *Store) Races() ([]Race, error) {
+	rows, err := s.db.Query("SELECT id, name, created_at FROM races ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var races []Race
+	for rows.Next() {
+		var r Race
+		var created string
+		if err := rows.Scan(&r.ID, &r.Name, &created); err != nil {
+			return nil, err
+		}
+		r.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		races = append(races, r)
+	}
+	return races, rows.Err()
+}
+
+func (s *Store) Config(name string) (*config.Config, error) {
+	var raw string
+
+	err := s.db.QueryRow("SELECT config FROM races WHERE name = ?", name).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrRaceNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return config.ParseConfig([]byte(raw))
+}
+
+// Events returns the events of a race ordered by time, in the race time
+// zone. Events sharing a timestamp keep the order in which they were stored.
+func (s *Store) Events(name string) ([]*event.Event, error) {
+	race, err := s.Race(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := s.Config(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(
+		"SELECT time, event_id, competitor_id, params FROM events WHERE race_id = ? ORDER BY time, id",
+		race.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*event.Event
+	for rows.Next() {
+		var ts, params string
+		e := &event.Event{ExtraParams: []string{}}
+
+		if err := rows.Scan(&ts, &e.EventID, &e.CompetitorID, &params); err != nil {
+			return nil, err
+		}
+
+		t, err := time.Parse(TIME_FORMAT, ts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stored time %q: %v", ts, err)
+		}
+		e.Time = t.In(cfg.Location)
+		e.Dated = true
+		if params != "" {
+			e.ExtraParams = strings.Fields(params)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// SaveOutput replaces the stored logs and results of a race.
+func (s *Store) SaveOutput(name string, logs, results []string) error {
+	race, err := s.Race(name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM results WHERE race_id = ?", race.ID); err != nil {
+		return err
+	}
+
+	for kind, lines := range map[string][]string{KIND_LOG: logs, KIND_RESULT: results} {
+		for i, line := range lines {
+			_, err := tx.Exec(
+				"INSERT INTO results (race_id, kind, position, line) VALUES (?, ?, ?, ?)",
+				race.ID, kind, i, line,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to store output: %w", err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Output returns the stored lines of the given kind.
+func (s *Store) Output(name, kind string) ([]string, error) {
+	race, err := s.Race(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(
+		"SELECT line FROM results WHERE race_id = ? AND kind = ? ORDER BY position",
+		race.ID, kind,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"biathlon/corrections"
+	"biathlon/event"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfig = `{
+	"laps": 2,
+	"lapLen": 3500,
+	"penaltyLen": 150,
+	"firingLines": 2,
+	"start": "10:00:00.000",
+	"startDelta": "00:01:30"
+}`
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := Open(filepath.Join(t.TempDir(), "races.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func at(h, m, s int) time.Time {
+	return time.Date(0, 1, 1, h, m, s, 0, time.UTC)
+}
+
+func TestCreateRace_RoundTrip(t *testing.T) {
+	store := openTestStore(t)
+
+	events := []*event.Event{
+		{Time: at(9, 30, 0), EventID: 1, CompetitorID: 1, ExtraParams: []string{}},
+		{Time: at(9, 31, 0), EventID: 2, CompetitorID: 1, ExtraParams: []string{"10:00:00.000"}},
+	}
+
+	if _, err := store.CreateRace("sprint", []byte(testConfig), events); err != nil {
+		t.Fatalf("CreateRace() error = %v", err)
+	}
+
+	cfg, err := store.Config("sprint")
+	if err != nil {
+		t.Fatalf("Config() error = %v", err)
+	}
+	if cfg.Laps != 2 || cfg.StartDelta != 90*time.Second {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+
+	got, err := store.Events("sprint")
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(got))
+	}
+	if !got[1].Time.Equal(at(9, 31, 0)) || got[1].EventID != 2 || got[1].ExtraParams[0] != "10:00:00.000" {
+		t.Errorf("Unexpected event %+v", got[1])
+	}
+}
+
+func TestEvents_TimeZone(t *testing.T) {
+	store := openTestStore(t)
+
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	cfg := `{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "firingLines": 2,
+		"start": "10:00:00.000", "startDelta": "00:01:30", "date": "2026-01-10", "timezone": "Europe/Oslo"}`
+	registered := time.Date(2026, 1, 10, 9, 31, 49, 285000000, oslo)
+
+	events := []*event.Event{{Time: registered, EventID: 1, CompetitorID: 1, ExtraParams: []string{}}}
+	if _, err := store.CreateRace("oslo", []byte(cfg), events); err != nil {
+		t.Fatalf("CreateRace() error = %v", err)
+	}
+
+	got, err := store.Events("oslo")
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	if !got[0].Time.Equal(registered) {
+		t.Errorf("Expected %v, got %v", registered, got[0].Time)
+	}
+	if got[0].Time.Location().String() != "Europe/Oslo" {
+		t.Errorf("Expected time in Europe/Oslo, got %v", got[0].Time.Location())
+	}
+}
+
+func TestAppendEvents_OrderedByTime(t *testing.T) {
+	store := openTestStore(t)
+
+	if _, err := store.CreateRace("sprint", []byte(testConfig), []*event.Event{
+		{Time: at(9, 30, 0), EventID: 1, CompetitorID: 1},
+		{Time: at(9, 40, 0), EventID: 1, CompetitorID: 3},
+	}); err != nil {
+		t.Fatalf("CreateRace() error = %v", err)
+	}
+
+	if err := store.AppendEvents("sprint", []*event.Event{
+		{Time: at(9, 35, 0), EventID: 1, CompetitorID: 2},
+	}); err != nil {
+		t.Fatalf("AppendEvents() error = %v", err)
+	}
+
+	got, err := store.Events("sprint")
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	for i, e := range got {
+		if e.CompetitorID != i+1 {
+			t.Errorf("Expected competitor %d at position %d, got %d", i+1, i, e.CompetitorID)
+		}
+	}
+}
+
+func TestAppendCorrections(t *testing.T) {
+	store := openTestStore(t)
+
+	if _, err := store.CreateRace("sprint", []byte(testConfig), nil); err != nil {
+		t.Fatalf("CreateRace() error = %v", err)
+	}
+
+	for i, text := range []string{"disqualify [10:05:00.000] 1 False start", "penalty [10:06:00.000] 2 00:01:00"} {
+		c, err := corrections.ParseCorrection(text)
+		if err != nil {
+			t.Fatalf("ParseCorrection() error = %v", err)
+		}
+		c.Line = i + 2
+		if err := store.AppendCorrections("sprint", []*corrections.Correction{c}); err != nil {
+			t.Fatalf("AppendCorrections() error = %v", err)
+		}
+	}
+
+	got, err := store.Corrections("sprint")
+	if err != nil {
+		t.Fatalf("Corrections() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 corrections, got %d", len(got))
+	}
+	if got[0].Action != corrections.ACTION_DISQUALIFY || got[0].Line != 2 || got[1].Action != corrections.ACTION_PENALTY {
+		t.Errorf("Unexpected corrections %+v %+v", got[0], got[1])
+	}
+}
+
+func TestSaveOutput(t *testing.T) {
+	store := openTestStore(t)
+
+	if _, err := store.CreateRace("sprint", []byte(testConfig), nil); err != nil {
+		t.Fatalf("CreateRace() error = %v", err)
+	}
+
+	if err := store.SaveOutput("sprint", []string{"log1", "log2"}, []string{"res1"}); err != nil {
+		t.Fatalf("SaveOutput() error = %v", err)
+	}
+	if err := store.SaveOutput("sprint", []string{"log3"}, []string{"res2"}); err != nil {
+		t.Fatalf("SaveOutput() error = %v", err)
+	}
+
+	logs, err := store.Output("sprint", KIND_LOG)
+	if err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if len(logs) != 1 || logs[0] != "log3" {
+		t.Errorf("Expected [log3], got %v", logs)
+	}
+}
+
+func TestRace_NotFound(t *testing.T) {
+	store := openTestStore(t)
+
+	_, err := store.Race("missing")
+	if !errors.Is(err, ErrRaceNotFound) {
+		t.Errorf("Expected ErrRaceNotFound, got %v", err)
+	}
+}
+
+func TestCreateRace_InvalidConfig(t *testing.T) {
+	store := openTestStore(t)
+
+	if _, err := store.CreateRace("sprint", []byte("{"), nil); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
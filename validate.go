@@ -0,0 +1,29 @@
+package main
+
+import (
+	"biathlon/config"
+	"errors"
+	"fmt"
+)
+
+func runValidateConfig(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: validate-config <config_path>")
+	}
+
+	_, err := config.LoadConfig(args[0])
+
+	var verrs config.ValidationErrors
+	if errors.As(err, &verrs) {
+		for _, e := range verrs {
+			fmt.Println(e.Error())
+		}
+		return fmt.Errorf("%s: %d problem(s) found", args[0], len(verrs))
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: config is valid\n", args[0])
+	return nil
+}